@@ -6,7 +6,7 @@ import (
 	"net/http"
 )
 
-//iwrapper:target
+//iwrapper:target base:"true" value:"true"
 type ResponseWriter interface {
 	//iwrapper:require
 	http.ResponseWriter
@@ -1,25 +1,130 @@
 // Code generated by iwrapper; DO NOT EDIT.
 package example
 
-import "net/http"
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
 
-func ResponseWriterWrapper(v http.ResponseWriter, wrapper func(http.ResponseWriter) ResponseWriter) http.ResponseWriter {
-	wrapped := wrapper(v)
-	var i uint64
-	const (
-		i0 = 1 << iota
-		i1
-		i2
-	)
+type ResponseWriterCapability uint64
+
+const (
+	ResponseWriterHijacker ResponseWriterCapability = 1 << iota
+	ResponseWriterCloseNotifier
+	ResponseWriterFlusher
+)
+
+func (c ResponseWriterCapability) String() string {
+	var s string
+	for i, name := range [...]string{"http.Hijacker", "http.CloseNotifier", "http.Flusher"} {
+		if c&(1<<i) != 0 {
+			s += "|" + name
+		}
+	}
+	if s == "" {
+		return "none"
+	}
+	return s[1:]
+}
+
+func ResponseWriterCapabilities(v http.ResponseWriter) ResponseWriterCapability {
+	var i ResponseWriterCapability
 	if _, ok := v.(http.Hijacker); ok {
-		i |= i0
+		i |= ResponseWriterHijacker
 	}
 	if _, ok := v.(http.CloseNotifier); ok {
-		i |= i1
+		i |= ResponseWriterCloseNotifier
 	}
 	if _, ok := v.(http.Flusher); ok {
-		i |= i2
+		i |= ResponseWriterFlusher
 	}
+	return i
+}
+
+type ResponseWriterBase struct {
+	Value http.ResponseWriter
+}
+
+func (b ResponseWriterBase) Header() http.Header {
+	return b.Value.Header()
+}
+
+func (b ResponseWriterBase) Write(arg []byte) (int, error) {
+	return b.Value.Write(arg)
+}
+
+func (b ResponseWriterBase) WriteHeader(statusCode int) {
+	b.Value.WriteHeader(statusCode)
+}
+
+func (b ResponseWriterBase) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return b.Value.(http.Hijacker).Hijack()
+}
+
+func (b ResponseWriterBase) CloseNotify() <-chan bool {
+	return b.Value.(http.CloseNotifier).CloseNotify()
+}
+
+func (b ResponseWriterBase) Flush() {
+	b.Value.(http.Flusher).Flush()
+}
+
+func ResponseWriterWrapper(v http.ResponseWriter, wrapper func(http.ResponseWriter) ResponseWriter) http.ResponseWriter {
+	i := ResponseWriterCapabilities(v)
+	wrapped := wrapper(v)
+	switch i {
+	case 0b0:
+		return struct {
+			http.ResponseWriter
+		}{wrapped}
+	case 0b1:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+		}{wrapped, wrapped}
+	case 0b10:
+		return struct {
+			http.ResponseWriter
+			http.CloseNotifier
+		}{wrapped, wrapped}
+	case 0b11:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.CloseNotifier
+		}{wrapped, wrapped, wrapped}
+	case 0b100:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{wrapped, wrapped}
+	case 0b101:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Flusher
+		}{wrapped, wrapped, wrapped}
+	case 0b110:
+		return struct {
+			http.ResponseWriter
+			http.CloseNotifier
+			http.Flusher
+		}{wrapped, wrapped, wrapped}
+	case 0b111:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.CloseNotifier
+			http.Flusher
+		}{wrapped, wrapped, wrapped, wrapped}
+	}
+	return v
+}
+
+func ResponseWriterWrapperWithValue(v http.ResponseWriter, w ResponseWriter) http.ResponseWriter {
+	i := ResponseWriterCapabilities(v)
+	wrapped := w
 	switch i {
 	case 0b0:
 		return struct {
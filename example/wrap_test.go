@@ -0,0 +1,32 @@
+package example
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The benchmarks compare the wrapper function with the wrapper value.
+// Both allocate the size, the MyResponseWriter stored in the interface and the returned struct,
+// since the closure of the wrapper function does not escape.
+func BenchmarkResponseWriterWrapper(b *testing.B) {
+	rec := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		size := 0
+		_ = ResponseWriterWrapper(rec, func(w http.ResponseWriter) ResponseWriter {
+			return MyResponseWriter{ResponseWriterBase{w}, &size}
+		})
+	}
+}
+
+func BenchmarkResponseWriterWrapperWithValue(b *testing.B) {
+	rec := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		size := 0
+		_ = ResponseWriterWrapperWithValue(rec, MyResponseWriter{ResponseWriterBase{rec}, &size})
+	}
+}
@@ -4,7 +4,7 @@ go 1.25.0
 
 require golang.org/x/tools v0.44.0
 
-require golang.org/x/sync v0.20.0 // indirect
+require golang.org/x/sync v0.20.0
 
 require (
 	github.com/google/go-cmp v0.7.0
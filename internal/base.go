@@ -0,0 +1,196 @@
+package iwrapper
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+var (
+	ErrInvalidBase = errors.New("invalid base")
+)
+
+// baseFieldName is the name of the field of the base struct holding the original value.
+const baseFieldName = "Value"
+
+// baseMethod is a method of the base struct, forwarded to the original value through the interface providing it.
+type baseMethod struct {
+	fn *types.Func
+	// intrfc is the optional interface providing the method, nil for the methods of the required interfaces.
+	intrfc    *Interface
+	localPath string
+}
+
+// baseMethods returns the methods of the base struct, in the order of the required and the optional interfaces.
+// Methods shared by several interfaces are forwarded through the first interface providing them.
+// The methods of the provided interfaces are left out unless the required interfaces have them,
+// since the original value may not implement them and the wrapper embedding the base must implement them itself.
+func baseMethods(conf *GenerateConfig) []*baseMethod {
+	provided := map[string]struct{}{}
+	var methods []*baseMethod
+	add := func(intrfc *Interface, optional bool) {
+		for _, fn := range intrfc.methodFuncs() {
+			if _, ok := provided[fn.Name()]; ok {
+				continue
+			}
+			provided[fn.Name()] = struct{}{}
+
+			method := &baseMethod{
+				fn:        fn,
+				localPath: intrfc.typ.localPath,
+			}
+			if optional {
+				method.intrfc = intrfc
+			}
+			methods = append(methods, method)
+		}
+	}
+
+	for _, intrfc := range conf.RequireInterface.interfaces {
+		add(intrfc, false)
+	}
+	for _, intrfc := range conf.ProvidedInterfaces {
+		for _, fn := range intrfc.methodFuncs() {
+			provided[fn.Name()] = struct{}{}
+		}
+	}
+	for _, intrfc := range conf.OptionalInterfaces {
+		add(intrfc, true)
+	}
+
+	return methods
+}
+
+// baseFieldIdent returns the name of the field holding the original value, which must differ from the method names.
+func baseFieldIdent(methods []*baseMethod) string {
+	n := newNamer()
+	for _, method := range methods {
+		n.reserve(method.fn.Name())
+	}
+
+	return n.fresh(baseFieldName)
+}
+
+// baseDecls returns the declarations of the base struct implementing every method of the target
+// except the provided interfaces by forwarding it to the original value:
+//
+//	type ResponseWriterBase struct {
+//		Value http.ResponseWriter
+//	}
+//
+//	func (b ResponseWriterBase) Write(p []byte) (int, error) {
+//		return b.Value.Write(p)
+//	}
+//
+//	func (b ResponseWriterBase) Flush() {
+//		b.Value.(http.Flusher).Flush()
+//	}
+func baseDecls(im *importer, conf *GenerateConfig, reserved []string) []ast.Decl {
+	methods := baseMethods(conf)
+	fieldName := baseFieldIdent(methods)
+
+	decls := make([]ast.Decl, 0, len(methods)+1)
+	decls = append(decls, &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name:       ast.NewIdent(conf.BaseName),
+			TypeParams: typeParamFieldList(im, conf.TypeParams),
+			Type: &ast.StructType{
+				Fields: &ast.FieldList{
+					List: []*ast.Field{{
+						Names: []*ast.Ident{ast.NewIdent(fieldName)},
+						Type:  conf.RequireInterface.Expr(im),
+					}},
+				},
+			},
+		}},
+	})
+
+	for _, method := range methods {
+		decls = append(decls, baseMethodDecl(im, conf, method, fieldName, reserved))
+	}
+
+	return decls
+}
+
+// baseMethodDecl returns the declaration of the method of the base struct forwarding the call to the original value.
+func baseMethodDecl(im *importer, conf *GenerateConfig, method *baseMethod, fieldName string, reserved []string) *ast.FuncDecl {
+	sig := method.fn.Type().(*types.Signature)
+
+	// the parameters are named to be passed to the original value, and the results are unnamed
+	params := make([]*types.Var, 0, sig.Params().Len())
+	for j := range sig.Params().Len() {
+		param := sig.Params().At(j)
+		name := param.Name()
+		if name == "" || name == "_" {
+			name = "arg"
+		}
+		params = append(params, types.NewParam(param.Pos(), param.Pkg(), name, param.Type()))
+	}
+	results := make([]*types.Var, 0, sig.Results().Len())
+	for j := range sig.Results().Len() {
+		result := sig.Results().At(j)
+		results = append(results, types.NewParam(result.Pos(), result.Pkg(), "", result.Type()))
+	}
+	sig = types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), sig.Variadic())
+
+	funcType := (&Type{typ: sig, localPath: method.localPath}).Expr(im).(*ast.FuncType)
+
+	var paramIdents []*ast.Ident
+	for _, field := range funcType.Params.List {
+		paramIdents = append(paramIdents, field.Names...)
+	}
+
+	args := make([]ast.Expr, 0, len(paramIdents))
+	for _, ident := range paramIdents {
+		args = append(args, ident)
+	}
+
+	receiverIdent := ast.NewIdent("b")
+	var value ast.Expr = &ast.SelectorExpr{
+		X:   receiverIdent,
+		Sel: ast.NewIdent(fieldName),
+	}
+	if method.intrfc != nil {
+		value = &ast.TypeAssertExpr{
+			X:    value,
+			Type: method.intrfc.Expr(im),
+		}
+	}
+
+	call := &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   value,
+			Sel: ast.NewIdent(method.fn.Name()),
+		},
+		Args: args,
+	}
+	if sig.Variadic() {
+		call.Ellipsis = 1
+	}
+
+	var stmt ast.Stmt = &ast.ExprStmt{X: call}
+	if sig.Results().Len() != 0 {
+		stmt = &ast.ReturnStmt{
+			Results: []ast.Expr{call},
+		}
+	}
+
+	funcDecl := &ast.FuncDecl{
+		Recv: &ast.FieldList{
+			List: []*ast.Field{{
+				Names: []*ast.Ident{receiverIdent},
+				Type:  instantiate(ast.NewIdent(conf.BaseName), conf.TypeParams),
+			}},
+		},
+		Name: ast.NewIdent(method.fn.Name()),
+		Type: funcType,
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{stmt},
+		},
+	}
+	renameLocals(funcDecl, reserved, append([]*ast.Ident{receiverIdent}, paramIdents...))
+
+	return funcDecl
+}
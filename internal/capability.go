@@ -0,0 +1,339 @@
+package iwrapper
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCapabilities = errors.New("invalid capabilities")
+)
+
+// capabilityNames returns the names of the capability constants of the optional interfaces,
+// e.g. ResponseWriterFlusher for http.Flusher of ResponseWriter.
+// Inline methods are named after the methods, and names colliding with the taken ones are numbered.
+func capabilityNames(n *namer, structName string, optionalInterfaces []*Interface) []string {
+	names := make([]string, 0, len(optionalInterfaces))
+	for _, intrfc := range optionalInterfaces {
+		var sb strings.Builder
+		sb.WriteString(structName)
+		if len(intrfc.methods) != 0 {
+			for _, method := range intrfc.methods {
+				sb.WriteString(upperFirst(method.name))
+			}
+		} else {
+			sb.WriteString(upperFirst(intrfc.name))
+		}
+
+		names = append(names, n.fresh(sb.String()))
+	}
+
+	return names
+}
+
+// capabilityLabel returns the label of the optional interface in the String method of the capability type.
+func capabilityLabel(intrfc *Interface) string {
+	if len(intrfc.methods) == 0 {
+		return intrfc.String()
+	}
+
+	names := make([]string, 0, len(intrfc.methods))
+	for _, method := range intrfc.methods {
+		names = append(names, method.name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// capabilityDecls returns the declarations of the capability type of the target, its constants and its String method:
+//
+//	type ResponseWriterCapability uint64
+//
+//	const (
+//		ResponseWriterHijacker ResponseWriterCapability = 1 << iota
+//		ResponseWriterFlusher
+//	)
+//
+//	func (c ResponseWriterCapability) String() string {
+//		var s string
+//		for i, name := range [...]string{"http.Hijacker", "http.Flusher"} {
+//			if c&(1<<i) != 0 {
+//				s += "|" + name
+//			}
+//		}
+//		if s == "" {
+//			return "none"
+//		}
+//		return s[1:]
+//	}
+func capabilityDecls(conf *GenerateConfig, constIdents []*ast.Ident, reserved []string) []ast.Decl {
+	typeDecl := &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name: ast.NewIdent(conf.CapabilityName),
+			Type: ast.NewIdent("uint64"),
+		}},
+	}
+
+	constSpecs := make([]ast.Spec, 0, len(constIdents))
+	for i, ident := range constIdents {
+		spec := &ast.ValueSpec{
+			Names: []*ast.Ident{ident},
+		}
+		if i == 0 {
+			spec.Type = ast.NewIdent(conf.CapabilityName)
+			spec.Values = []ast.Expr{&ast.BinaryExpr{
+				X: &ast.BasicLit{
+					Kind:  token.INT,
+					Value: "1",
+				},
+				Op: token.SHL,
+				Y:  ast.NewIdent("iota"),
+			}}
+		}
+		constSpecs = append(constSpecs, spec)
+	}
+
+	constDecl := &ast.GenDecl{
+		Tok:   token.CONST,
+		Specs: constSpecs,
+	}
+
+	labels := make([]ast.Expr, 0, len(conf.OptionalInterfaces))
+	for _, intrfc := range conf.OptionalInterfaces {
+		labels = append(labels, &ast.BasicLit{
+			Kind:  token.STRING,
+			Value: strconv.Quote(capabilityLabel(intrfc)),
+		})
+	}
+
+	var (
+		receiverIdent = ast.NewIdent("c")
+		strIdent      = ast.NewIdent("s")
+		indexIdent    = ast.NewIdent("i")
+		nameIdent     = ast.NewIdent("name")
+	)
+	stringDecl := &ast.FuncDecl{
+		Recv: &ast.FieldList{
+			List: []*ast.Field{{
+				Names: []*ast.Ident{receiverIdent},
+				Type:  ast.NewIdent(conf.CapabilityName),
+			}},
+		},
+		Name: ast.NewIdent("String"),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{
+					Type: ast.NewIdent("string"),
+				}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.DeclStmt{
+					Decl: &ast.GenDecl{
+						Tok: token.VAR,
+						Specs: []ast.Spec{&ast.ValueSpec{
+							Names: []*ast.Ident{strIdent},
+							Type:  ast.NewIdent("string"),
+						}},
+					},
+				},
+				&ast.RangeStmt{
+					Key:   indexIdent,
+					Value: nameIdent,
+					Tok:   token.DEFINE,
+					X: &ast.CompositeLit{
+						Type: &ast.ArrayType{
+							Len: &ast.Ellipsis{},
+							Elt: ast.NewIdent("string"),
+						},
+						Elts: labels,
+					},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{&ast.IfStmt{
+							Cond: &ast.BinaryExpr{
+								X: &ast.BinaryExpr{
+									X:  receiverIdent,
+									Op: token.AND,
+									Y: &ast.ParenExpr{
+										X: &ast.BinaryExpr{
+											X: &ast.BasicLit{
+												Kind:  token.INT,
+												Value: "1",
+											},
+											Op: token.SHL,
+											Y:  indexIdent,
+										},
+									},
+								},
+								Op: token.NEQ,
+								Y: &ast.BasicLit{
+									Kind:  token.INT,
+									Value: "0",
+								},
+							},
+							Body: &ast.BlockStmt{
+								List: []ast.Stmt{&ast.AssignStmt{
+									Lhs: []ast.Expr{strIdent},
+									Tok: token.ADD_ASSIGN,
+									Rhs: []ast.Expr{&ast.BinaryExpr{
+										X: &ast.BasicLit{
+											Kind:  token.STRING,
+											Value: strconv.Quote("|"),
+										},
+										Op: token.ADD,
+										Y:  nameIdent,
+									}},
+								}},
+							},
+						}},
+					},
+				},
+				&ast.IfStmt{
+					Cond: &ast.BinaryExpr{
+						X:  strIdent,
+						Op: token.EQL,
+						Y: &ast.BasicLit{
+							Kind:  token.STRING,
+							Value: strconv.Quote(""),
+						},
+					},
+					Body: &ast.BlockStmt{
+						List: []ast.Stmt{&ast.ReturnStmt{
+							Results: []ast.Expr{&ast.BasicLit{
+								Kind:  token.STRING,
+								Value: strconv.Quote("none"),
+							}},
+						}},
+					},
+				},
+				&ast.ReturnStmt{
+					Results: []ast.Expr{&ast.SliceExpr{
+						X: strIdent,
+						Low: &ast.BasicLit{
+							Kind:  token.INT,
+							Value: "1",
+						},
+					}},
+				},
+			},
+		},
+	}
+	renameLocals(stringDecl, reserved, []*ast.Ident{receiverIdent, strIdent, indexIdent, nameIdent})
+
+	return []ast.Decl{typeDecl, constDecl, stringDecl}
+}
+
+// capabilitiesFuncDecl returns the declaration of the function detecting the capabilities of the value:
+//
+//	func ResponseWriterCapabilities(v http.ResponseWriter) ResponseWriterCapability {
+//		var i ResponseWriterCapability
+//		if _, ok := v.(http.Hijacker); ok {
+//			i |= ResponseWriterHijacker
+//		}
+//		...
+//		return i
+//	}
+func capabilitiesFuncDecl(im *importer, conf *GenerateConfig, constIdents []*ast.Ident, optionalExprs []ast.Expr, reserved []string) *ast.FuncDecl {
+	var (
+		valueIdent = ast.NewIdent("v")
+		indexIdent = ast.NewIdent("i")
+		okIdent    = ast.NewIdent("ok")
+	)
+
+	valueType := conf.RequireInterface.Expr(im)
+	detect, locals := detectStmts(conf, valueType, valueIdent, indexIdent, okIdent, constIdents, nil, optionalExprs, nil)
+
+	bodyStmts := make([]ast.Stmt, 0, len(detect)+1)
+	bodyStmts = append(bodyStmts, detect...)
+	bodyStmts = append(bodyStmts, &ast.ReturnStmt{
+		Results: []ast.Expr{indexIdent},
+	})
+
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent(conf.CapabilitiesFuncName),
+		Type: &ast.FuncType{
+			TypeParams: typeParamFieldList(im, conf.TypeParams),
+			Params: &ast.FieldList{
+				List: []*ast.Field{{
+					Names: []*ast.Ident{valueIdent},
+					Type:  valueType,
+				}},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{
+					Type: ast.NewIdent(conf.CapabilityName),
+				}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: bodyStmts,
+		},
+	}
+	renameLocals(funcDecl, reserved, append([]*ast.Ident{valueIdent, indexIdent, okIdent}, locals...))
+
+	return funcDecl
+}
+
+// detectStmts returns the statements detecting the optional interfaces the value implements into the mask,
+// looking them up through the Unwrap chain if the target has the lookup.
+// The capabilities are used only by the wrapper function, and are nil for the capabilities function.
+func detectStmts(
+	conf *GenerateConfig,
+	valueType ast.Expr,
+	valueIdent, indexIdent, okIdent *ast.Ident,
+	constIdents, capIdents []*ast.Ident,
+	optionalExprs []ast.Expr,
+	usedCaps []bool,
+) ([]ast.Stmt, []*ast.Ident) {
+	stmts := []ast.Stmt{&ast.DeclStmt{
+		Decl: &ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{&ast.ValueSpec{
+				Names: []*ast.Ident{indexIdent},
+				Type:  ast.NewIdent(conf.CapabilityName),
+			}},
+		},
+	}}
+
+	for j, expr := range optionalExprs {
+		stmts = append(stmts, &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent("_"), okIdent},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.TypeAssertExpr{
+					X:    valueIdent,
+					Type: expr,
+				}},
+			},
+			Cond: okIdent,
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{&ast.AssignStmt{
+					Lhs: []ast.Expr{indexIdent},
+					Tok: token.OR_ASSIGN,
+					Rhs: []ast.Expr{constIdents[j]},
+				}},
+			},
+		})
+	}
+
+	if conf.LookupDepth == 0 {
+		return stmts, nil
+	}
+
+	if usedCaps == nil {
+		usedCaps = make([]bool, len(optionalExprs))
+	}
+	lookup, locals := lookupStmts(
+		conf.LookupDepth, valueType,
+		valueIdent, indexIdent, okIdent,
+		constIdents, capIdents, optionalExprs, usedCaps,
+	)
+
+	return append(stmts, lookup...), locals
+}
@@ -0,0 +1,54 @@
+package iwrapper
+
+import "testing"
+
+func TestCapabilityNames(t *testing.T) {
+	t.Parallel()
+
+	http := &Package{name: "http", path: "net/http"}
+
+	testCases := []struct {
+		description string
+		reserved    []string
+		optional    []*Interface
+		expected    []string
+	}{{
+		description: "targetの名前とinterfaceの名前を繋げる",
+		optional:    []*Interface{{pkg: http, name: "Hijacker"}, {pkg: http, name: "Flusher"}},
+		expected:    []string{"ResponseWriterHijacker", "ResponseWriterFlusher"},
+	}, {
+		description: "inlineのmethodはmethod名を繋げる",
+		optional: []*Interface{{
+			name:    "responseWriterSetReadDeadlineSetWriteDeadline",
+			methods: []*Method{{name: "SetReadDeadline"}, {name: "SetWriteDeadline"}},
+		}},
+		expected: []string{"ResponseWriterSetReadDeadlineSetWriteDeadline"},
+	}, {
+		description: "同名のinterfaceや使われている名前には番号が付く",
+		reserved:    []string{"ResponseWriterFlusher"},
+		optional: []*Interface{
+			{pkg: &Package{name: "rand", path: "math/rand"}, name: "Source"},
+			{pkg: &Package{name: "rand", path: "math/rand/v2"}, name: "Source"},
+			{pkg: http, name: "Flusher"},
+		},
+		expected: []string{"ResponseWriterSource", "ResponseWriterSource1", "ResponseWriterFlusher1"},
+	}, {
+		description: "unexportedなinterfaceも大文字で繋げる",
+		optional:    []*Interface{{name: "flusher"}},
+		expected:    []string{"ResponseWriterFlusher"},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			n := newNamer()
+			n.reserve(testCase.reserved...)
+
+			names := capabilityNames(n, "ResponseWriter", testCase.optional)
+			if diff := diff(testCase.expected, names); diff != "" {
+				t.Errorf("names: (-expected +actual)\n%s", diff)
+			}
+		})
+	}
+}
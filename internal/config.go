@@ -1,50 +1,32 @@
 package iwrapper
 
 import (
+	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/token"
-	"strconv"
+	"go/types"
 )
 
 type Package struct {
-	name          string
-	path          string
-	isRequireName bool
+	name string
+	path string
 }
 
-func NewPackage(name, path string, isRequireName bool) *Package {
+func NewPackage(name, path string) *Package {
 	return &Package{
-		name:          name,
-		path:          path,
-		isRequireName: isRequireName,
+		name: name,
+		path: path,
 	}
 }
 
-func (p *Package) ID() string {
-	return p.name
-}
-
-func (p *Package) Expr() ast.Expr {
-	return ast.NewIdent(p.name)
+func newPackageFromTypes(pkg *types.Package) *Package {
+	return NewPackage(pkg.Name(), pkg.Path())
 }
 
-func (p *Package) ImportSpec() *ast.ImportSpec {
-	if p.isRequireName {
-		return &ast.ImportSpec{
-			Name: ast.NewIdent(p.name),
-			Path: &ast.BasicLit{
-				Kind:  token.STRING,
-				Value: strconv.Quote(p.path),
-			},
-		}
-	}
-
-	return &ast.ImportSpec{
-		Path: &ast.BasicLit{
-			Kind:  token.STRING,
-			Value: strconv.Quote(p.path),
-		},
-	}
+// Expr returns the name referring to the package, importing it.
+func (p *Package) Expr(im *importer) ast.Expr {
+	return ast.NewIdent(im.name(p))
 }
 
 type AnonymousInterface struct {
@@ -57,26 +39,21 @@ func NewAnonymousInterface(interfaces []*Interface) *AnonymousInterface {
 	}
 }
 
-func (ai *AnonymousInterface) Expr() ([]*Package, ast.Expr) {
+func (ai *AnonymousInterface) Expr(im *importer) ast.Expr {
 	switch len(ai.interfaces) {
 	// if the interface has only one interface, return that interface
 	case 1:
-		pkg, expr := ai.interfaces[0].Expr()
-
-		return []*Package{pkg}, expr
+		return ai.interfaces[0].Expr(im)
 	// if the interface has more than one interface, return the named interface
 	default:
-		pkgs := make([]*Package, 0, len(ai.interfaces))
 		fieldList := make([]*ast.Field, 0, len(ai.interfaces))
 		for _, intrfc := range ai.interfaces {
-			pkg, expr := intrfc.Expr()
-			pkgs = append(pkgs, pkg)
 			fieldList = append(fieldList, &ast.Field{
-				Type: expr,
+				Type: intrfc.Expr(im),
 			})
 		}
 
-		return pkgs, &ast.InterfaceType{
+		return &ast.InterfaceType{
 			Methods: &ast.FieldList{
 				List: fieldList,
 			},
@@ -86,22 +63,24 @@ func (ai *AnonymousInterface) Expr() ([]*Package, ast.Expr) {
 
 type NamedInterface struct {
 	name       string
+	typeParams []*TypeParam
 	interfaces []*Interface
 	declared   bool
 }
 
-func NewNamedInterface(name string, interfaces []*Interface, declared bool) *NamedInterface {
+func NewNamedInterface(name string, typeParams []*TypeParam, interfaces []*Interface, declared bool) *NamedInterface {
 	return &NamedInterface{
 		name:       name,
+		typeParams: typeParams,
 		interfaces: interfaces,
 		declared:   declared,
 	}
 }
 
-func (ni *NamedInterface) Expr() ast.Expr {
+func (ni *NamedInterface) Expr(im *importer) ast.Expr {
 	// if the interface is already declared, return the named interface
 	if ni.declared {
-		return ast.NewIdent(ni.name)
+		return instantiate(ast.NewIdent(ni.name), ni.typeParams)
 	}
 
 	switch len(ni.interfaces) {
@@ -114,45 +93,33 @@ func (ni *NamedInterface) Expr() ast.Expr {
 		}
 	// if the interface has only one interface, return that interface
 	case 1:
-		_, expr := ni.interfaces[0].Expr()
-
-		return expr
+		return ni.interfaces[0].Expr(im)
 	// if the interface has more than one interface, return the named interface
 	default:
-		return ast.NewIdent(ni.name)
+		return instantiate(ast.NewIdent(ni.name), ni.typeParams)
 	}
 }
 
-func (n *NamedInterface) Decl() ([]*Package, *ast.GenDecl) {
-	// if declared or empty, no declaration is needed
-	if n.declared || len(n.interfaces) == 0 {
-		return nil, nil
+func (n *NamedInterface) Decl(im *importer) *ast.GenDecl {
+	// if declared, empty or only one interface, no declaration is needed
+	if n.declared || len(n.interfaces) <= 1 {
+		return nil
 	}
 
-	// if the interface has only one interface, import of the dependency is needed
-	if len(n.interfaces) == 1 {
-		pkg, _ := n.interfaces[0].Expr()
-		if pkg == nil {
-			return nil, nil
-		}
-
-		return []*Package{pkg}, nil
-	}
+	typeParamList := typeParamFieldList(im, n.typeParams)
 
-	pkgs := make([]*Package, 0, len(n.interfaces))
 	fieldList := make([]*ast.Field, 0, len(n.interfaces))
 	for _, intrfc := range n.interfaces {
-		pkg, expr := intrfc.Expr()
-		pkgs = append(pkgs, pkg)
 		fieldList = append(fieldList, &ast.Field{
-			Type: expr,
+			Type: intrfc.Expr(im),
 		})
 	}
 
-	return pkgs, &ast.GenDecl{
+	return &ast.GenDecl{
 		Tok: token.TYPE,
 		Specs: []ast.Spec{&ast.TypeSpec{
-			Name: ast.NewIdent(n.name),
+			Name:       ast.NewIdent(n.name),
+			TypeParams: typeParamList,
 			Type: &ast.InterfaceType{
 				Methods: &ast.FieldList{
 					List: fieldList,
@@ -163,24 +130,186 @@ func (n *NamedInterface) Decl() ([]*Package, *ast.GenDecl) {
 }
 
 type Interface struct {
-	pkg  *Package
-	name string
+	pkg      *Package
+	name     string
+	typeArgs []*Type
+	typ      *Type
+	// methods and typeParams are set only for the anonymous interfaces of inline methods,
+	// which are declared in the generated code.
+	methods    []*Method
+	typeParams []*TypeParam
 }
 
-func NewInterface(pkg *Package, name string) *Interface {
+func NewInterface(pkg *Package, name string, typ *Type, typeArgs ...*Type) *Interface {
 	return &Interface{
-		pkg:  pkg,
-		name: name,
+		pkg:      pkg,
+		name:     name,
+		typeArgs: typeArgs,
+		typ:      typ,
+	}
+}
+
+func NewInlineInterface(name string, typ *Type, typeParams []*TypeParam, methods []*Method) *Interface {
+	return &Interface{
+		name:       name,
+		typ:        typ,
+		methods:    methods,
+		typeParams: typeParams,
 	}
 }
 
-func (i *Interface) Expr() (*Package, ast.Expr) {
+// Decl returns the declaration of the anonymous interface. Non-anonymous interfaces need no declaration.
+func (i *Interface) Decl(im *importer) *ast.GenDecl {
+	if len(i.methods) == 0 {
+		return nil
+	}
+
+	typeParamList := typeParamFieldList(im, i.typeParams)
+
+	fieldList := make([]*ast.Field, 0, len(i.methods))
+	for _, method := range i.methods {
+		fieldList = append(fieldList, method.Field(im))
+	}
+
+	return &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name:       ast.NewIdent(i.name),
+			TypeParams: typeParamList,
+			Type: &ast.InterfaceType{
+				Methods: &ast.FieldList{
+					List: fieldList,
+				},
+			},
+		}},
+	}
+}
+
+func (i *Interface) Expr(im *importer) ast.Expr {
+	// the anonymous interfaces are declared with the type parameters of the target
+	if len(i.methods) != 0 {
+		return instantiate(ast.NewIdent(i.name), i.typeParams)
+	}
+
+	var expr ast.Expr
 	if i.pkg == nil {
-		return nil, ast.NewIdent(i.name)
+		expr = ast.NewIdent(i.name)
+	} else {
+		expr = &ast.SelectorExpr{
+			X:   i.pkg.Expr(im),
+			Sel: ast.NewIdent(i.name),
+		}
+	}
+
+	if len(i.typeArgs) == 0 {
+		return expr
+	}
+
+	indices := make([]ast.Expr, 0, len(i.typeArgs))
+	for _, typeArg := range i.typeArgs {
+		indices = append(indices, typeArg.Expr(im))
+	}
+
+	return &ast.IndexListExpr{
+		X:       expr,
+		Indices: indices,
+	}
+}
+
+// Type is a type appearing in the generated code, such as a type argument or a type constraint.
+type Type struct {
+	typ       types.Type
+	localPath string
+}
+
+func NewType(typ types.Type, localPkg *types.Package) *Type {
+	var localPath string
+	if localPkg != nil {
+		localPath = localPkg.Path()
+	}
+
+	return &Type{
+		typ:       typ,
+		localPath: localPath,
+	}
+}
+
+func (t *Type) Expr(im *importer) ast.Expr {
+	str := types.TypeString(t.typ, im.qualifier(t.localPath))
+
+	expr, err := parser.ParseExpr(str)
+	if err != nil {
+		// the types containing the invalid type are reported when the targets are parsed,
+		// and types.TypeString returns a valid type expression for the others
+		panic(fmt.Sprintf("failed to parse type(%s): %v", str, err))
+	}
+
+	return expr
+}
+
+type Method struct {
+	name      string
+	signature *Type
+}
+
+func NewMethod(name string, signature *Type) *Method {
+	return &Method{
+		name:      name,
+		signature: signature,
+	}
+}
+
+func (m *Method) Field(im *importer) *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(m.name)},
+		Type:  m.signature.Expr(im),
+	}
+}
+
+type TypeParam struct {
+	name       string
+	constraint *Type
+}
+
+func NewTypeParam(name string, constraint *Type) *TypeParam {
+	return &TypeParam{
+		name:       name,
+		constraint: constraint,
+	}
+}
+
+// typeParamFieldList returns the type parameter list for the declarations of generic functions and types.
+func typeParamFieldList(im *importer, typeParams []*TypeParam) *ast.FieldList {
+	if len(typeParams) == 0 {
+		return nil
+	}
+
+	fieldList := make([]*ast.Field, 0, len(typeParams))
+	for _, typeParam := range typeParams {
+		fieldList = append(fieldList, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(typeParam.name)},
+			Type:  typeParam.constraint.Expr(im),
+		})
+	}
+
+	return &ast.FieldList{
+		List: fieldList,
+	}
+}
+
+// instantiate instantiates the generic type with its own type parameters.
+func instantiate(expr ast.Expr, typeParams []*TypeParam) ast.Expr {
+	if len(typeParams) == 0 {
+		return expr
+	}
+
+	indices := make([]ast.Expr, 0, len(typeParams))
+	for _, typeParam := range typeParams {
+		indices = append(indices, ast.NewIdent(typeParam.name))
 	}
 
-	return i.pkg, &ast.SelectorExpr{
-		X:   i.pkg.Expr(),
-		Sel: ast.NewIdent(i.name),
+	return &ast.IndexListExpr{
+		X:       expr,
+		Indices: indices,
 	}
 }
@@ -1,7 +1,17 @@
 package iwrapper
 
-import "github.com/google/go-cmp/cmp"
+import (
+	"go/types"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
 
 func diff[T any](x, y T) string {
-	return cmp.Diff(x, y, cmp.AllowUnexported(Package{}, AnonymousInterface{}, NamedInterface{}, Interface{}))
+	return cmp.Diff(x, y,
+		cmp.AllowUnexported(Package{}, AnonymousInterface{}, NamedInterface{}, Interface{}, Type{}, TypeParam{}, Method{}),
+		cmpopts.IgnoreInterfaces(struct{ types.Type }{}),
+		cmpopts.IgnoreFields(Interface{}, "typ"),
+		cmpopts.IgnoreFields(ParseResult{}, "Pos", "Scope"),
+	)
 }
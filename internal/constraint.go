@@ -0,0 +1,257 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type ConstraintKind int
+
+const (
+	// ConstraintGroup means the interfaces are implemented all together or not at all.
+	ConstraintGroup ConstraintKind = iota
+	// ConstraintImplies means the first interface is implemented only with all the others.
+	ConstraintImplies
+	// ConstraintExcludes means the interfaces are never implemented together.
+	ConstraintExcludes
+)
+
+func (ck ConstraintKind) String() string {
+	switch ck {
+	case ConstraintGroup:
+		return "group"
+	case ConstraintImplies:
+		return "implies"
+	case ConstraintExcludes:
+		return "excludes"
+	default:
+		return fmt.Sprintf("ConstraintKind(%d)", int(ck))
+	}
+}
+
+// Constraint restricts the combinations of the optional interfaces generated for a target.
+type Constraint struct {
+	Kind       ConstraintKind
+	Interfaces []*Interface
+}
+
+var (
+	ErrInvalidConstraint = errors.New("invalid constraint")
+)
+
+// createConstraints creates the constraints from the directives on the elements of a target.
+//
+//	//iwrapper:group <name>
+//	//iwrapper:implies <element>...
+//	//iwrapper:excludes <element>...
+//
+// Elements are referred to by the type expression as written in the target, or by a method name for inline methods.
+func createConstraints(elements []*element) ([]*Constraint, error) {
+	keyMap := map[string]*element{}
+	for _, elem := range elements {
+		for _, key := range elem.keys {
+			keyMap[elementKey(key)] = elem
+		}
+	}
+
+	var (
+		constraints []*Constraint
+		groupNames  []string
+		groupMap    = map[string][]*Interface{}
+	)
+	for _, elem := range elements {
+		if elem.doc == nil {
+			continue
+		}
+
+		required := isRequired(elem.doc)
+		for _, comment := range elem.doc.List {
+			if args, ok := directiveArgs(comment.Text, groupDirectivePrefix); ok {
+				if len(args) != 1 {
+					return nil, fmt.Errorf("%w: %s needs exactly one group name", ErrInvalidConstraint, groupDirectivePrefix)
+				}
+				if required {
+					return nil, fmt.Errorf("%w: required interface(%s) can not be grouped", ErrInvalidConstraint, elem.intrfc)
+				}
+
+				name := args[0]
+				if _, ok := groupMap[name]; !ok {
+					groupNames = append(groupNames, name)
+				}
+				groupMap[name] = append(groupMap[name], elem.intrfc)
+
+				continue
+			}
+
+			kind := ConstraintImplies
+			args, ok := directiveArgs(comment.Text, impliesDirectivePrefix)
+			if !ok {
+				kind = ConstraintExcludes
+				args, ok = directiveArgs(comment.Text, excludesDirectivePrefix)
+				if !ok {
+					continue
+				}
+			}
+
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%w: %s needs elements", ErrInvalidConstraint, kind)
+			}
+			if required {
+				return nil, fmt.Errorf("%w: required interface(%s) can not have %s", ErrInvalidConstraint, elem.intrfc, kind)
+			}
+
+			for _, arg := range args {
+				target, ok := keyMap[elementKey(arg)]
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown element(%s) in %s", ErrInvalidConstraint, arg, kind)
+				}
+				if isRequired(target.doc) {
+					return nil, fmt.Errorf("%w: required interface(%s) in %s", ErrInvalidConstraint, arg, kind)
+				}
+				if target == elem {
+					return nil, fmt.Errorf("%w: %s refers to itself(%s)", ErrInvalidConstraint, kind, arg)
+				}
+
+				constraints = append(constraints, &Constraint{
+					Kind:       kind,
+					Interfaces: []*Interface{elem.intrfc, target.intrfc},
+				})
+			}
+		}
+	}
+
+	for _, name := range groupNames {
+		// a group of a single interface does not restrict anything
+		if len(groupMap[name]) < 2 {
+			continue
+		}
+
+		constraints = append(constraints, &Constraint{
+			Kind:       ConstraintGroup,
+			Interfaces: groupMap[name],
+		})
+	}
+
+	return constraints, nil
+}
+
+// directiveArgs returns the space separated arguments of the directive if the comment is the directive.
+// Spaces inside brackets do not separate the arguments, so that e.g. Batcher[K, V] is a single argument.
+func directiveArgs(text, prefix string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(text, prefix)
+	if !ok {
+		return nil, false
+	}
+
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+
+	var (
+		args  []string
+		depth int
+		start = -1
+	)
+	for i, r := range rest {
+		switch {
+		case r == '[' || r == '(':
+			depth++
+		case r == ']' || r == ')':
+			depth--
+		case depth <= 0 && unicode.IsSpace(r):
+			if start >= 0 {
+				args = append(args, rest[start:i])
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		args = append(args, rest[start:])
+	}
+
+	return args, true
+}
+
+// elementKey normalizes the name referring to an element by removing the spaces,
+// so that the type expression matches however the spaces are written (e.g. Batcher[K, V] and Batcher[K,V]).
+func elementKey(name string) string {
+	return strings.Join(strings.Fields(name), "")
+}
+
+// maskConstraint is a constraint represented with the masks of the optional interfaces.
+type maskConstraint struct {
+	kind ConstraintKind
+	// mask is the interfaces of the group, the implied interfaces, or the excluded pair
+	mask uint64
+	// from is the implying interface
+	from uint64
+}
+
+func newMaskConstraints(optionalInterfaces []*Interface, constraints []*Constraint) ([]*maskConstraint, error) {
+	indexMap := make(map[*Interface]int, len(optionalInterfaces))
+	for i, intrfc := range optionalInterfaces {
+		indexMap[intrfc] = i
+	}
+
+	maskConstraints := make([]*maskConstraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		var masks []uint64
+		for _, intrfc := range constraint.Interfaces {
+			index, ok := indexMap[intrfc]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is not optional", ErrInvalidConstraint, intrfc)
+			}
+			masks = append(masks, 1<<index)
+		}
+
+		maskConstraint := &maskConstraint{
+			kind: constraint.Kind,
+		}
+		if constraint.Kind == ConstraintImplies {
+			maskConstraint.from, masks = masks[0], masks[1:]
+		}
+		for _, mask := range masks {
+			maskConstraint.mask |= mask
+		}
+
+		maskConstraints = append(maskConstraints, maskConstraint)
+	}
+
+	return maskConstraints, nil
+}
+
+// allows reports whether the combination of the optional interfaces satisfies the constraint.
+func (mc *maskConstraint) allows(mask uint64) bool {
+	switch mc.kind {
+	case ConstraintGroup:
+		return mask&mc.mask == 0 || mask&mc.mask == mc.mask
+	case ConstraintImplies:
+		return mask&mc.from == 0 || mask&mc.mask == mc.mask
+	case ConstraintExcludes:
+		return mask&mc.mask != mc.mask
+	default:
+		return true
+	}
+}
+
+// bits returns the interfaces the constraint depends on.
+func (mc *maskConstraint) bits() uint64 {
+	return mc.mask | mc.from
+}
+
+func allowsAll(constraints []*maskConstraint, mask uint64) bool {
+	for _, constraint := range constraints {
+		if !constraint.allows(mask) {
+			return false
+		}
+	}
+
+	return true
+}
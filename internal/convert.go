@@ -1,24 +1,135 @@
 package iwrapper
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
 func Convert(results []*ParseResult) ([]*GenerateConfig, error) {
 	generateConfigs := make([]*GenerateConfig, 0, len(results))
+	var errs []error
 	for _, result := range results {
 		funcName := result.FuncName
 		if funcName == "" {
 			funcName = result.StructName + "Wrapper"
 		}
 
-		wrappedInterfaces := make([]*Interface, 0, len(result.RequiredInterfaces)+len(result.OptionalInterfaces))
+		wrappedInterfaces := make([]*Interface, 0, len(result.RequiredInterfaces)+len(result.ProvidedInterfaces)+len(result.OptionalInterfaces))
 		wrappedInterfaces = append(wrappedInterfaces, result.RequiredInterfaces...)
+		wrappedInterfaces = append(wrappedInterfaces, result.ProvidedInterfaces...)
 		wrappedInterfaces = append(wrappedInterfaces, result.OptionalInterfaces...)
 
+		if err := checkMethodConflicts(wrappedInterfaces); err != nil {
+			errs = append(errs, NewDiagnostic(result.Pos, fmt.Errorf("invalid target(%s): %w", result.StructName, err)))
+			continue
+		}
+
+		var unwrapFunc string
+		if result.Unwrap {
+			if err := checkUnwrapConflict(wrappedInterfaces); err != nil {
+				errs = append(errs, NewDiagnostic(result.Pos, fmt.Errorf("invalid target(%s): %w", result.StructName, err)))
+				continue
+			}
+			unwrapFunc = unwrapFuncName(result.StructName)
+		}
+
+		maxCases := result.MaxCases
+		if maxCases == 0 {
+			maxCases = DefaultMaxCases
+		}
+
+		// the cases are planned only for the optional interfaces which are not hidden
+		var hidden []bool
+		if len(result.Hidden) != 0 {
+			hidden = make([]bool, len(result.OptionalInterfaces))
+			for j, intrfc := range result.OptionalInterfaces {
+				hidden[j] = slices.Contains(result.Hidden, intrfc)
+			}
+		}
+		shown, constraints := shownInterfaces(result.OptionalInterfaces, result.Hidden, result.Constraints)
+
+		var casePlan *CasePlan
+		var err error
+		if result.Observed != nil {
+			observed := result.Observed
+			if hidden != nil {
+				observed = make([]uint64, 0, len(result.Observed))
+				for _, mask := range result.Observed {
+					observed = append(observed, compressMask(mask, hidden))
+				}
+			}
+			casePlan, err = NewObservedCasePlan(shown, constraints, observed, maxCases, result.Fallback)
+		} else {
+			casePlan, err = NewCasePlan(shown, constraints, maxCases)
+		}
+		if err != nil {
+			errs = append(errs, NewDiagnostic(result.Pos, fmt.Errorf("invalid target(%s): %w", result.StructName, err)))
+			continue
+		}
+
+		var capabilityName, capabilitiesFuncName string
+		if len(result.OptionalInterfaces) != 0 {
+			capabilityName = result.StructName + "Capability"
+			capabilitiesFuncName = result.StructName + "Capabilities"
+		}
+
+		var withCapabilitiesFuncName string
+		if result.Capabilities {
+			withCapabilitiesFuncName = funcName + "WithCapabilities"
+		}
+
+		var withFilterFuncName string
+		if result.Filter {
+			withFilterFuncName = funcName + "WithFilter"
+		}
+
+		var withValueFuncName string
+		if result.Value {
+			withValueFuncName = funcName + "WithValue"
+		}
+
+		var baseName string
+		if result.Base {
+			baseName = result.StructName + "Base"
+		}
+
+		var intersect []bool
+		if len(result.Intersected) != 0 {
+			intersect = make([]bool, len(result.OptionalInterfaces))
+			for j, intrfc := range result.OptionalInterfaces {
+				intersect[j] = slices.Contains(result.Intersected, intrfc)
+			}
+		}
+
 		generateConfigs = append(generateConfigs, &GenerateConfig{
-			FuncName:           funcName,
-			RequireInterface:   NewAnonymousInterface(result.RequiredInterfaces),
-			WrappedInterface:   NewNamedInterface(result.StructName, wrappedInterfaces, true),
-			OptionalInterfaces: result.OptionalInterfaces,
+			Pos:                      result.Pos,
+			Scope:                    result.Scope,
+			Unwrap:                   result.Unwrap,
+			Mode:                     result.Mode,
+			Intersect:                intersect,
+			Hidden:                   hidden,
+			LookupDepth:              result.LookupDepth,
+			CapabilityName:           capabilityName,
+			CapabilitiesFuncName:     capabilitiesFuncName,
+			WithCapabilitiesFuncName: withCapabilitiesFuncName,
+			WithFilterFuncName:       withFilterFuncName,
+			WithValueFuncName:        withValueFuncName,
+			UnwrapFuncName:           unwrapFunc,
+			BaseName:                 baseName,
+			FuncName:                 funcName,
+			TypeParams:               result.TypeParams,
+			RequireInterface:         NewAnonymousInterface(result.RequiredInterfaces),
+			WrappedInterface:         NewNamedInterface(result.StructName, result.TypeParams, wrappedInterfaces, !result.Declare),
+			OptionalInterfaces:       result.OptionalInterfaces,
+			ProvidedInterfaces:       result.ProvidedInterfaces,
+			CasePlan:                 casePlan,
 		})
 	}
 
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
 	return generateConfigs, nil
 }
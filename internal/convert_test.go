@@ -0,0 +1,55 @@
+package iwrapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvertMethodConflict(t *testing.T) {
+	t.Parallel()
+
+	_, results, err := ParseTarget("testdata/invalid/conflict.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Convert(results)
+	if !errors.Is(err, ErrMethodConflict) {
+		t.Errorf("error: expected %v, got %v", ErrMethodConflict, err)
+	}
+
+	diagnostics := Diagnostics(err)
+	if len(diagnostics) != 1 {
+		t.Fatalf("diagnostics: expected %d, got %d", 1, len(diagnostics))
+	}
+
+	if line := diagnostics[0].Pos.Line; line != 12 {
+		t.Errorf("line: expected %d, got %d", 12, line)
+	}
+
+	expectedMessage := "invalid target(Conflict): method conflict: method Write of ConflictWriter(func(s string) error) conflicts with method Write of http.ResponseWriter(func([]byte) (int, error))"
+	if message := diagnostics[0].Err.Error(); message != expectedMessage {
+		t.Errorf("error message: expected %q, got %q", expectedMessage, message)
+	}
+}
+
+func TestConvertUnwrapConflict(t *testing.T) {
+	t.Parallel()
+
+	_, results, err := ParseTarget("testdata/invalid/unwrap_conflict.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Convert(results)
+	if !errors.Is(err, ErrMethodConflict) {
+		t.Errorf("error: expected %v, got %v", ErrMethodConflict, err)
+	}
+
+	expectedMessage := "invalid target(UnwrapConflict): method conflict: method Unwrap of Unwrapper(func() net/http.ResponseWriter) conflicts with the generated method"
+	for _, diagnostic := range Diagnostics(err) {
+		if message := diagnostic.Err.Error(); message != expectedMessage {
+			t.Errorf("error message: expected %q, got %q", expectedMessage, message)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+var (
+	ErrInvalidMode      = errors.New("invalid mode")
+	ErrInvalidIntersect = errors.New("invalid intersect")
+)
+
+// Mode is how the wrappers provide the optional interfaces.
+type Mode int
+
+const (
+	// ModeImplement requires the value returned by the wrapper function to implement the target,
+	// and the wrappers provide every optional interface by it.
+	ModeImplement Mode = iota
+	// ModeDelegate requires the value returned by the wrapper function to implement only the required interfaces.
+	// The wrappers provide the optional interfaces it implements by it, and the others by the original value.
+	ModeDelegate
+	// ModeIntersect requires the value returned by the wrapper function to implement only the required interfaces.
+	// The wrappers provide only the optional interfaces implemented by both the original value and it.
+	ModeIntersect
+)
+
+// ParseMode parses the name of the mode.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "implement":
+		return ModeImplement, nil
+	case "delegate":
+		return ModeDelegate, nil
+	case "intersect":
+		return ModeIntersect, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrInvalidMode, name)
+	}
+}
+
+func (m Mode) String() string {
+	switch m {
+	case ModeImplement:
+		return "implement"
+	case ModeDelegate:
+		return "delegate"
+	case ModeIntersect:
+		return "intersect"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// wrapperResultExpr returns the type of the value returned by the wrapper function given by the caller.
+func (conf *GenerateConfig) wrapperResultExpr(im *importer) ast.Expr {
+	if conf.Mode != ModeImplement {
+		interfaces := make([]*Interface, 0, len(conf.RequireInterface.interfaces)+len(conf.ProvidedInterfaces))
+		interfaces = append(interfaces, conf.RequireInterface.interfaces...)
+		interfaces = append(interfaces, conf.ProvidedInterfaces...)
+
+		return NewAnonymousInterface(interfaces).Expr(im)
+	}
+
+	return conf.WrappedInterface.Expr(im)
+}
+
+// intersects reports whether the optional interface is provided only if both the original value and the wrapped value implement it.
+func (conf *GenerateConfig) intersects(j int) bool {
+	return conf.Mode == ModeIntersect || (j < len(conf.Intersect) && conf.Intersect[j])
+}
+
+// intersectedInterfaces returns the optional interfaces with the intersect directive.
+//
+//	//iwrapper:intersect
+func intersectedInterfaces(elements []*element) ([]*Interface, error) {
+	var intersected []*Interface
+	for _, elem := range elements {
+		if elem.doc == nil {
+			continue
+		}
+
+		for _, comment := range elem.doc.List {
+			args, ok := directiveArgs(comment.Text, intersectDirectivePrefix)
+			if !ok {
+				continue
+			}
+
+			if len(args) != 0 {
+				return nil, fmt.Errorf("%w: %s takes no arguments", ErrInvalidIntersect, intersectDirectivePrefix)
+			}
+			if isRequired(elem.doc) {
+				return nil, fmt.Errorf("%w: required interface(%s) can not be intersected", ErrInvalidIntersect, elem.intrfc)
+			}
+			if isProvided(elem.doc) {
+				return nil, fmt.Errorf("%w: provided interface(%s) can not be intersected", ErrInvalidIntersect, elem.intrfc)
+			}
+
+			intersected = append(intersected, elem.intrfc)
+		}
+	}
+
+	return intersected, nil
+}
+
+// intersectStmt returns the statement providing the optional interface by the wrapped value,
+// or removing it from the capabilities if the wrapped value does not implement it:
+//
+//	if impl, ok := wrapped.(http.Hijacker); ok {
+//		c1 = impl
+//	} else {
+//		i &^= ResponseWriterHijacker
+//	}
+func intersectStmt(
+	wrappedValueIdent, indexIdent, implIdent, okIdent, constIdent, capIdent *ast.Ident,
+	optionalExpr ast.Expr,
+) ast.Stmt {
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{implIdent, okIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.TypeAssertExpr{
+				X:    wrappedValueIdent,
+				Type: optionalExpr,
+			}},
+		},
+		Cond: okIdent,
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.AssignStmt{
+				Lhs: []ast.Expr{capIdent},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{implIdent},
+			}},
+		},
+		Else: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.AssignStmt{
+				Lhs: []ast.Expr{indexIdent},
+				Tok: token.AND_NOT_ASSIGN,
+				Rhs: []ast.Expr{constIdent},
+			}},
+		},
+	}
+}
+
+// delegateStmt returns the statement choosing the implementation of the optional interface,
+// which is the wrapped value if it implements the optional interface, and otherwise the original value.
+// With the lookup, the capability already holds the inner value implementing the optional interface, if any.
+//
+//	if impl, ok := wrapped.(http.Flusher); ok {
+//		c0 = impl
+//	} else if c0 == nil {
+//		c0, _ = v.(http.Flusher)
+//	}
+func delegateStmt(
+	lookup bool,
+	valueIdent, wrappedValueIdent, implIdent, okIdent, capIdent *ast.Ident,
+	optionalExpr ast.Expr,
+) ast.Stmt {
+	var elseStmt ast.Stmt = &ast.BlockStmt{
+		List: []ast.Stmt{&ast.AssignStmt{
+			Lhs: []ast.Expr{capIdent, ast.NewIdent("_")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.TypeAssertExpr{
+				X:    valueIdent,
+				Type: optionalExpr,
+			}},
+		}},
+	}
+	if lookup {
+		elseStmt = &ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  capIdent,
+				Op: token.EQL,
+				Y:  ast.NewIdent("nil"),
+			},
+			Body: elseStmt.(*ast.BlockStmt),
+		}
+	}
+
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{implIdent, okIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.TypeAssertExpr{
+				X:    wrappedValueIdent,
+				Type: optionalExpr,
+			}},
+		},
+		Cond: okIdent,
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.AssignStmt{
+				Lhs: []ast.Expr{capIdent},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{implIdent},
+			}},
+		},
+		Else: elseStmt,
+	}
+}
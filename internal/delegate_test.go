@@ -0,0 +1,143 @@
+package iwrapper
+
+import "testing"
+
+func TestDelegateBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target mode:"delegate"
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	Closer
+}
+`, `package behavior
+
+import "testing"
+
+func TestDelegate(t *testing.T) {
+	v := fullWriter{writer{"orig"}}
+	w := TargetWrapper(v, func(Writer) Writer {
+		return flushWriter{writer{"wrapper"}}
+	})
+
+	if got := w.Write(); got != "wrapper.Write" {
+		t.Errorf("Write: expected wrapper.Write, got %s", got)
+	}
+
+	// the wrapped value implementing the optional interface receives the call
+	f, ok := w.(Flusher)
+	if !ok {
+		t.Fatal("Flusher is not provided")
+	}
+	if got := f.Flush(); got != "wrapper.Flush" {
+		t.Errorf("Flush: expected wrapper.Flush, got %s", got)
+	}
+
+	// the original value receives the call the wrapped value does not implement
+	c, ok := w.(Closer)
+	if !ok {
+		t.Fatal("Closer is not delegated to the original value")
+	}
+	if got := c.Close(); got != "orig.Close" {
+		t.Errorf("Close: expected orig.Close, got %s", got)
+	}
+}
+
+func TestDelegateOriginalLacks(t *testing.T) {
+	v := flushWriter{writer{"orig"}}
+	w := TargetWrapper(v, func(Writer) Writer {
+		return fullWriter{writer{"wrapper"}}
+	})
+
+	// the optional interfaces are provided only if the original value implements them
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer is provided although the original value does not implement it")
+	}
+	if f, ok := w.(Flusher); !ok || f.Flush() != "wrapper.Flush" {
+		t.Error("Flusher is not provided by the wrapped value")
+	}
+}
+`)
+}
+
+func TestIntersectBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target mode:"intersect"
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	Closer
+}
+
+//iwrapper:target mode:"delegate"
+type Partial interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	//iwrapper:intersect
+	Closer
+}
+`, `package behavior
+
+import "testing"
+
+func TestIntersect(t *testing.T) {
+	v := flushWriter{writer{"orig"}}
+	w := TargetWrapper(v, func(Writer) Writer {
+		return closeWriter{writer{"wrapper"}}
+	})
+
+	// each optional interface is implemented by only one of the values
+	if _, ok := w.(Flusher); ok {
+		t.Error("Flusher of only the original value is provided")
+	}
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer of only the wrapped value is provided")
+	}
+}
+
+func TestIntersectBoth(t *testing.T) {
+	v := fullWriter{writer{"orig"}}
+	w := TargetWrapper(v, func(Writer) Writer {
+		return flushWriter{writer{"wrapper"}}
+	})
+
+	// the wrapped value receives the call of the interface both values implement
+	f, ok := w.(Flusher)
+	if !ok {
+		t.Fatal("Flusher of both values is not provided")
+	}
+	if got := f.Flush(); got != "wrapper.Flush" {
+		t.Errorf("Flush: expected wrapper.Flush, got %s", got)
+	}
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer of only the original value is provided")
+	}
+}
+
+func TestIntersectDirective(t *testing.T) {
+	v := fullWriter{writer{"orig"}}
+	w := PartialWrapper(v, func(Writer) Writer {
+		return writer{"wrapper"}
+	})
+
+	// the interface without the directive is delegated to the original value
+	f, ok := w.(Flusher)
+	if !ok {
+		t.Fatal("Flusher is not delegated to the original value")
+	}
+	if got := f.Flush(); got != "orig.Flush" {
+		t.Errorf("Flush: expected orig.Flush, got %s", got)
+	}
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer with the intersect directive is provided although the wrapped value does not implement it")
+	}
+}
+`)
+}
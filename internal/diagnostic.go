@@ -0,0 +1,124 @@
+package iwrapper
+
+import (
+	"encoding/json"
+	"errors"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic is a problem found while generating wrappers, with the position in the source causing it.
+type Diagnostic struct {
+	// Pos is the position of the problem. It is invalid if the problem has no position.
+	Pos token.Position
+	Err error
+}
+
+func NewDiagnostic(pos token.Position, err error) *Diagnostic {
+	return &Diagnostic{
+		Pos: pos,
+		Err: err,
+	}
+}
+
+func (d *Diagnostic) Error() string {
+	if !d.Pos.IsValid() {
+		return d.Err.Error()
+	}
+
+	return d.Pos.String() + ": " + d.Err.Error()
+}
+
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+type diagnosticJSON struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d *Diagnostic) MarshalJSON() ([]byte, error) {
+	return json.Marshal(diagnosticJSON{
+		File:    d.Pos.Filename,
+		Line:    d.Pos.Line,
+		Column:  d.Pos.Column,
+		Message: d.Err.Error(),
+	})
+}
+
+// Diagnostics flattens the error into the diagnostics it consists of.
+// Errors joined with errors.Join are split, and a wrapped error without diagnostics becomes a diagnostic without position.
+func Diagnostics(err error) []*Diagnostic {
+	if err == nil {
+		return nil
+	}
+
+	if diagnostic, ok := err.(*Diagnostic); ok {
+		return []*Diagnostic{diagnostic}
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var diagnostics []*Diagnostic
+		for _, e := range joined.Unwrap() {
+			diagnostics = append(diagnostics, Diagnostics(e)...)
+		}
+
+		return diagnostics
+	}
+
+	// the context of the wrapping error is dropped, since diagnostics describe the problems by themselves
+	var diagnostic *Diagnostic
+	if errors.As(err, &diagnostic) {
+		return Diagnostics(errors.Unwrap(err))
+	}
+
+	return []*Diagnostic{NewDiagnostic(token.Position{}, err)}
+}
+
+// withPosition attaches the position to the error, unless it already contains diagnostics.
+func withPosition(pos token.Position, err error) error {
+	var diagnostic *Diagnostic
+	if errors.As(err, &diagnostic) {
+		return err
+	}
+
+	return NewDiagnostic(pos, err)
+}
+
+// packageErrorPosition parses the position of the error reported by go/packages, in the form of `file:line:col`.
+func packageErrorPosition(pkgErr packages.Error) token.Position {
+	var pos token.Position
+	if pkgErr.Pos == "" || pkgErr.Pos == "-" {
+		return pos
+	}
+
+	rest := pkgErr.Pos
+	for _, field := range []*int{&pos.Column, &pos.Line} {
+		index := strings.LastIndex(rest, ":")
+		if index < 0 {
+			break
+		}
+
+		n, err := strconv.Atoi(rest[index+1:])
+		if err != nil {
+			break
+		}
+
+		*field = n
+		rest = rest[:index]
+	}
+
+	if pos.Line == 0 {
+		// only the line is reported
+		pos.Line, pos.Column = pos.Column, 0
+	}
+	pos.Filename = rest
+
+	return pos
+}
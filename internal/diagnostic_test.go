@@ -0,0 +1,115 @@
+package iwrapper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTargetDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := ParseTarget("testdata/invalid/multiple.go")
+	if err == nil {
+		t.Fatal("error: expected non-nil, got nil")
+	}
+
+	type position struct {
+		File         string
+		Line, Column int
+	}
+
+	expectedPositions := []position{
+		{File: "multiple.go", Line: 9, Column: 2},
+		{File: "multiple.go", Line: 10, Column: 2},
+		{File: "multiple.go", Line: 14, Column: 6},
+		{File: "multiple.go", Line: 23, Column: 2},
+		{File: "multiple.go", Line: 20, Column: 6},
+		{File: "multiple.go", Line: 20, Column: 6},
+	}
+
+	diagnostics := Diagnostics(err)
+	positions := make([]position, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		positions = append(positions, position{
+			File:   filepath.Base(diagnostic.Pos.Filename),
+			Line:   diagnostic.Pos.Line,
+			Column: diagnostic.Pos.Column,
+		})
+	}
+
+	if diff := diff(positions, expectedPositions); diff != "" {
+		t.Errorf("positions diff: %s", diff)
+	}
+
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("error: expected %v, got %v", ErrUnknownType, err)
+	}
+	if !errors.Is(err, ErrInvalidMaxCases) {
+		t.Errorf("error: expected %v, got %v", ErrInvalidMaxCases, err)
+	}
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("error: expected %v, got %v", ErrInvalidFilter, err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	pos := token.Position{Filename: "a.go", Line: 3, Column: 2}
+	errA, errB := errors.New("a"), errors.New("b")
+
+	testCases := []struct {
+		description string
+		err         error
+		expected    []string
+	}{{
+		description: "nilは空",
+		err:         nil,
+		expected:    nil,
+	}, {
+		description: "位置なしのエラーはそのまま",
+		err:         fmt.Errorf("context: %w", errA),
+		expected:    []string{"context: a"},
+	}, {
+		description: "joinされたエラーは分割する",
+		err:         errors.Join(NewDiagnostic(pos, errA), errB),
+		expected:    []string{"a.go:3:2: a", "b"},
+	}, {
+		description: "wrapされたdiagnosticは取り出す",
+		err:         fmt.Errorf("context: %w", errors.Join(NewDiagnostic(pos, errA), NewDiagnostic(pos, errB))),
+		expected:    []string{"a.go:3:2: a", "a.go:3:2: b"},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			var messages []string
+			for _, diagnostic := range Diagnostics(testCase.err) {
+				messages = append(messages, diagnostic.Error())
+			}
+
+			if diff := diff(messages, testCase.expected); diff != "" {
+				t.Errorf("messages diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnosticMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(NewDiagnostic(token.Position{Filename: "a.go", Line: 3, Column: 2}, errors.New("invalid")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"file":"a.go","line":3,"column":2,"message":"invalid"}`
+	if string(data) != expected {
+		t.Errorf("json: expected %s, got %s", expected, data)
+	}
+}
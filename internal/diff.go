@@ -0,0 +1,261 @@
+package iwrapper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes in a hunk.
+const diffContext = 3
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+type edit struct {
+	kind editKind
+	// oldIndex and newIndex are the indices of the line before the edit in the old and new lines
+	oldIndex, newIndex int
+	line               string
+}
+
+// UnifiedDiff returns the unified diff from old to new of the file at path.
+// It returns nil if they are the same.
+func UnifiedDiff(path string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	edits := diffLines(splitLines(old), splitLines(new))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", path, path)
+	for start := 0; start < len(edits); {
+		// skip to the first change
+		for start < len(edits) && edits[start].kind == editEqual {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// extend the hunk while the changes are close enough
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].kind != editEqual {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*diffContext {
+				break
+			}
+		}
+
+		hunkStart := max(start-diffContext, 0)
+		hunkEnd := min(end+diffContext, len(edits))
+		writeHunk(&buf, edits[hunkStart:hunkEnd])
+
+		start = hunkEnd
+	}
+
+	return buf.Bytes()
+}
+
+func writeHunk(buf *bytes.Buffer, edits []edit) {
+	var oldLen, newLen int
+	for _, e := range edits {
+		if e.kind != editInsert {
+			oldLen++
+		}
+		if e.kind != editDelete {
+			newLen++
+		}
+	}
+
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(edits[0].oldIndex, oldLen), hunkRange(edits[0].newIndex, newLen))
+	for _, e := range edits {
+		switch e.kind {
+		case editEqual:
+			buf.WriteString(" ")
+		case editDelete:
+			buf.WriteString("-")
+		case editInsert:
+			buf.WriteString("+")
+		}
+		buf.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(index, length int) string {
+	// empty ranges point to the line before them
+	if length == 0 {
+		return fmt.Sprintf("%d,0", index)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", index+1)
+	}
+
+	return fmt.Sprintf("%d,%d", index+1, length)
+}
+
+// splitLines splits the text into lines, keeping the line endings.
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// maxDiffEdits bounds the edits searched by the Myers' algorithm, whose trace takes O(D²) memory for D edits.
+// Beyond it, the changed lines are replaced as a whole.
+const maxDiffEdits = 1000
+
+// diffLines returns the edit script from a to b.
+// The common leading and trailing lines are kept as they are, and the lines between them are diffed by the Myers' algorithm.
+func diffLines(a, b []string) []edit {
+	var prefix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	var suffix int
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	middle, ok := myersDiff(middleA, middleB)
+	if !ok {
+		middle = replaceLines(middleA, middleB)
+	}
+
+	edits := make([]edit, 0, prefix+len(middle)+suffix)
+	for i := range prefix {
+		edits = append(edits, edit{kind: editEqual, oldIndex: i, newIndex: i, line: a[i]})
+	}
+	for _, e := range middle {
+		e.oldIndex += prefix
+		e.newIndex += prefix
+		edits = append(edits, e)
+	}
+	for i := range suffix {
+		x, y := len(a)-suffix+i, len(b)-suffix+i
+		edits = append(edits, edit{kind: editEqual, oldIndex: x, newIndex: y, line: a[x]})
+	}
+
+	return edits
+}
+
+// replaceLines returns the edits deleting all lines of a and inserting all lines of b.
+func replaceLines(a, b []string) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	for x, line := range a {
+		edits = append(edits, edit{kind: editDelete, oldIndex: x, newIndex: 0, line: line})
+	}
+	for y, line := range b {
+		edits = append(edits, edit{kind: editInsert, oldIndex: len(a), newIndex: y, line: line})
+	}
+
+	return edits
+}
+
+// myersDiff returns the shortest edit script from a to b by the Myers' algorithm.
+// It returns false if the script needs more than maxDiffEdits edits.
+func myersDiff(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	// one side is empty, so the only script replaces the lines
+	if n == 0 || m == 0 {
+		return replaceLines(a, b), true
+	}
+
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD
+
+	// trace[d] is the furthest x on the diagonals -d..d after d edits
+	var trace [][]int
+	v := make([]int, 2*maxD+2)
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	if !found {
+		return nil, false
+	}
+	// furthest returns the furthest x on the diagonal k after d edits
+	furthest := func(d, k int) int {
+		return trace[d][k+d]
+	}
+
+	// backtrack from the end to collect the edits in reverse order
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		k := x - y
+
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prevK := k - 1
+			if k == -d || (k != d && furthest(d-1, k-1) < furthest(d-1, k+1)) {
+				prevK = k + 1
+			}
+			prevX = furthest(d-1, prevK)
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: editEqual, oldIndex: x, newIndex: y, line: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{kind: editInsert, oldIndex: x, newIndex: y, line: b[y]})
+			} else {
+				x--
+				edits = append(edits, edit{kind: editDelete, oldIndex: x, newIndex: y, line: a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits, true
+}
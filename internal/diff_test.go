@@ -0,0 +1,91 @@
+package iwrapper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		old, new    string
+		expected    string
+	}{{
+		description: "同じ内容は差分なし",
+		old:         "a\nb\n",
+		new:         "a\nb\n",
+		expected:    "",
+	}, {
+		description: "変更行の前後3行を含める",
+		old:         "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+		new:         "1\n2\n3\n4\nx\n6\n7\n8\n9\n",
+		expected: "--- f.go\n+++ f.go\n" +
+			"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n",
+	}, {
+		description: "離れた変更は別のhunkにする",
+		old:         "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		new:         "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n",
+		expected: "--- f.go\n+++ f.go\n" +
+			"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+			"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+y\n",
+	}, {
+		description: "新規ファイルは全行追加",
+		old:         "",
+		new:         "a\nb\n",
+		expected:    "--- f.go\n+++ f.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+	}, {
+		description: "削除されたファイルは全行削除",
+		old:         "a\nb\n",
+		new:         "",
+		expected:    "--- f.go\n+++ f.go\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+	}, {
+		description: "末尾の改行なしを示す",
+		old:         "a\n",
+		new:         "a\nb",
+		expected:    "--- f.go\n+++ f.go\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			actual := string(UnifiedDiff("f.go", []byte(testCase.old), []byte(testCase.new)))
+			if diff := diff(actual, testCase.expected); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffManyEdits(t *testing.T) {
+	t.Parallel()
+
+	// the changed lines need more edits than maxDiffEdits, so they are replaced as a whole
+	var oldBuf, newBuf, expected strings.Builder
+	oldBuf.WriteString("head\n")
+	newBuf.WriteString("head\n")
+	lines := maxDiffEdits/2 + 1
+	for i := range lines {
+		fmt.Fprintf(&oldBuf, "old%d\n", i)
+		fmt.Fprintf(&newBuf, "new%d\n", i)
+	}
+	oldBuf.WriteString("tail\n")
+	newBuf.WriteString("tail\n")
+
+	fmt.Fprintf(&expected, "--- f.go\n+++ f.go\n@@ -1,%d +1,%d @@\n head\n", lines+2, lines+2)
+	for i := range lines {
+		fmt.Fprintf(&expected, "-old%d\n", i)
+	}
+	for i := range lines {
+		fmt.Fprintf(&expected, "+new%d\n", i)
+	}
+	expected.WriteString(" tail\n")
+
+	actual := string(UnifiedDiff("f.go", []byte(oldBuf.String()), []byte(newBuf.String())))
+	if diff := diff(actual, expected.String()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
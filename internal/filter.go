@@ -0,0 +1,45 @@
+package iwrapper
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+)
+
+var (
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// filterFuncType returns the type of the filter function given by the caller,
+// which receives the capabilities of the value and returns the capabilities to provide:
+//
+//	func(ResponseWriterCapability) ResponseWriterCapability
+func filterFuncType(conf *GenerateConfig) *ast.FuncType {
+	return &ast.FuncType{
+		Params: &ast.FieldList{
+			List: []*ast.Field{{
+				Type: ast.NewIdent(conf.CapabilityName),
+			}},
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{
+				Type: ast.NewIdent(conf.CapabilityName),
+			}},
+		},
+	}
+}
+
+// filterStmt returns the statement filtering the capabilities with the filter function.
+// The capabilities the value does not have are ignored, since the wrappers can not provide them.
+//
+//	i &= filter(i)
+func filterStmt(indexIdent, filterIdent *ast.Ident) ast.Stmt {
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{indexIdent},
+		Tok: token.AND_ASSIGN,
+		Rhs: []ast.Expr{&ast.CallExpr{
+			Fun:  filterIdent,
+			Args: []ast.Expr{indexIdent},
+		}},
+	}
+}
@@ -0,0 +1,58 @@
+package iwrapper
+
+import "testing"
+
+func TestFilterBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target filter:"true"
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	Closer
+}
+`, `package behavior
+
+import "testing"
+
+func wrapper(Writer) Target {
+	return fullWriter{writer{"wrapper"}}
+}
+
+func TestFilter(t *testing.T) {
+	v := fullWriter{writer{"orig"}}
+	var received TargetCapability
+	w := TargetWrapperWithFilter(v, wrapper, func(c TargetCapability) TargetCapability {
+		received = c
+		return c &^ TargetFlusher
+	})
+
+	if received != TargetFlusher|TargetCloser {
+		t.Errorf("filter: expected %s, got %s", TargetFlusher|TargetCloser, received)
+	}
+	if _, ok := w.(Flusher); ok {
+		t.Error("Flusher masked by the filter is provided")
+	}
+	if c, ok := w.(Closer); !ok || c.Close() != "wrapper.Close" {
+		t.Error("Closer is not provided by the wrapped value")
+	}
+}
+
+func TestFilterUnknownBits(t *testing.T) {
+	v := flushWriter{writer{"orig"}}
+	w := TargetWrapperWithFilter(v, wrapper, func(TargetCapability) TargetCapability {
+		return TargetFlusher | TargetCloser
+	})
+
+	// the bits the original value does not have are ignored
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer is provided although the original value does not implement it")
+	}
+	if _, ok := w.(Flusher); !ok {
+		t.Error("Flusher is not provided")
+	}
+}
+`)
+}
@@ -1,6 +1,7 @@
 package iwrapper
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -10,217 +11,456 @@ import (
 )
 
 type GenerateConfig struct {
+	// Pos is the position of the declaration of the target.
+	Pos                token.Position
 	FuncName           string
+	TypeParams         []*TypeParam
 	RequireInterface   *AnonymousInterface
 	WrappedInterface   *NamedInterface
 	OptionalInterfaces []*Interface
+	CasePlan           *CasePlan
+	// CapabilityName is the name of the type of the mask of the optional interfaces implemented by the value.
+	CapabilityName string
+	// CapabilitiesFuncName is the name of the function detecting the optional interfaces implemented by the value.
+	CapabilitiesFuncName string
+	// WithCapabilitiesFuncName is the name of the variant of the wrapper function passing the capabilities to the wrapper.
+	// Empty means the variant is not generated.
+	WithCapabilitiesFuncName string
+	// WithFilterFuncName is the name of the variant of the wrapper function providing only the capabilities
+	// returned by the filter function. Empty means the variant is not generated.
+	WithFilterFuncName string
+	// WithValueFuncName is the name of the variant of the wrapper function taking the wrapper value
+	// instead of the wrapper function. Empty means the variant is not generated.
+	WithValueFuncName string
+	// LookupDepth is the maximum number of Unwrap calls to look up the optional interfaces the value does not implement.
+	// 0 means only the value is checked.
+	LookupDepth int
+	// Scope is the names declared at the package level of the target, which the generated identifiers avoid.
+	Scope []string
+	// Unwrap is true if the wrappers have the Unwrap method returning the original value.
+	Unwrap bool
+	// UnwrapFuncName is the name of the function retrieving the innermost value through the Unwrap methods.
+	UnwrapFuncName string
+	// ProvidedInterfaces are the interfaces the wrapped value always implements, which the wrappers always provide.
+	ProvidedInterfaces []*Interface
+	// Mode is how the wrappers provide the optional interfaces.
+	Mode Mode
+	// Hidden is whether each optional interface is never provided by the wrappers, although the capabilities report it.
+	// The masks of the CasePlan are of the optional interfaces which are not hidden. nil means none.
+	Hidden []bool
+	// Intersect is whether each optional interface is provided only if both the original value and the wrapped value implement it,
+	// in addition to all optional interfaces in ModeIntersect. nil means none.
+	Intersect []bool
+	// BaseName is the name of the struct implementing the target by forwarding every method to the original value.
+	// Empty means the struct is not generated.
+	BaseName string
 }
 
+const generatedHeader = "// Code generated by iwrapper; DO NOT EDIT.\n"
+
+// Generate writes the wrappers of the targets.
 func Generate(w io.Writer, pkgName string, confs []*GenerateConfig) error {
-	fset := token.NewFileSet()
+	output, err := Render(pkgName, confs)
+	if err != nil {
+		return err
+	}
 
-	decls := []ast.Decl{}
+	if _, err := w.Write(output.Src); err != nil {
+		return fmt.Errorf("failed to write generated code: %w", err)
+	}
+
+	return nil
+}
+
+// Render generates the formatted source of the wrappers of the targets in memory.
+func Render(pkgName string, confs []*GenerateConfig) (*Output, error) {
+	fset := token.NewFileSet()
 
-	importPkgMap := map[string]*Package{}
-	importDecl := &ast.GenDecl{
-		Tok: token.IMPORT,
+	// the names declared in the package and the generated declarations can not be used by imports and locals
+	var reserved []string
+	for _, conf := range confs {
+		reserved = append(reserved, conf.Scope...)
+		reserved = append(reserved, conf.FuncName, conf.WrappedInterface.name)
+		if conf.Unwrap {
+			reserved = append(reserved, conf.unwrapperName(), conf.UnwrapFuncName)
+		}
+		if len(conf.OptionalInterfaces) != 0 {
+			reserved = append(reserved, conf.CapabilityName, conf.CapabilitiesFuncName)
+		}
+		if conf.WithCapabilitiesFuncName != "" {
+			reserved = append(reserved, conf.WithCapabilitiesFuncName)
+		}
+		if conf.WithFilterFuncName != "" {
+			reserved = append(reserved, conf.WithFilterFuncName)
+		}
+		if conf.WithValueFuncName != "" {
+			reserved = append(reserved, conf.WithValueFuncName)
+		}
+		if conf.BaseName != "" {
+			reserved = append(reserved, conf.BaseName)
+		}
 	}
-	decls = append(decls, importDecl)
 
+	// the capability constants are named after the optional interfaces, avoiding the other names
+	capabilityNamer := newNamer()
+	capabilityNamer.reserve(reserved...)
+	constNames := make([][]string, 0, len(confs))
 	for _, conf := range confs {
-		requireDepPkgs, valueType := conf.RequireInterface.Expr()
-		for _, pkg := range requireDepPkgs {
-			importPkgMap[pkg.ID()] = pkg
+		names := capabilityNames(capabilityNamer, conf.WrappedInterface.name, conf.OptionalInterfaces)
+		constNames = append(constNames, names)
+		reserved = append(reserved, names...)
+	}
+
+	im := newImporter()
+	im.reserve(reserved...)
+	decls := []ast.Decl{}
+	// owners are the targets generating each declaration
+	var owners []*GenerateConfig
+	// docs are the doc comments of the declarations, which are written with them
+	docs := map[ast.Decl]string{}
+
+	for confIndex, conf := range confs {
+		declNum := len(decls)
+
+		for _, intrfc := range conf.WrappedInterface.interfaces {
+			if decl := intrfc.Decl(im); decl != nil {
+				decls = append(decls, decl)
+			}
 		}
 
-		wrappedDepPkgs, wrappedDecl := conf.WrappedInterface.Decl()
-		if wrappedDecl != nil {
+		if wrappedDecl := conf.WrappedInterface.Decl(im); wrappedDecl != nil {
 			decls = append(decls, wrappedDecl)
 		}
-		for _, pkg := range wrappedDepPkgs {
-			importPkgMap[pkg.ID()] = pkg
+
+		var unwrapper *ast.Field
+		if conf.Unwrap {
+			decls = append(decls, unwrapperDecls(im, conf, reserved)...)
+			unwrapper = &ast.Field{
+				Type: instantiate(ast.NewIdent(conf.unwrapperName()), conf.TypeParams),
+			}
 		}
 
-		var (
-			valueIdent      = ast.NewIdent("v")
-			wrapFuncIdent   = ast.NewIdent("wrapper")
-			wrappedTypeExpr = conf.WrappedInterface.Expr()
-		)
+		constIdents := make([]*ast.Ident, 0, len(conf.OptionalInterfaces))
+		optionalExprs := make([]ast.Expr, 0, len(conf.OptionalInterfaces))
+		for j, intrfc := range conf.OptionalInterfaces {
+			constIdents = append(constIdents, ast.NewIdent(constNames[confIndex][j]))
+			optionalExprs = append(optionalExprs, intrfc.Expr(im))
+		}
+		if len(conf.OptionalInterfaces) != 0 {
+			decls = append(decls, capabilityDecls(conf, constIdents, reserved)...)
+			decls = append(decls, capabilitiesFuncDecl(im, conf, constIdents, optionalExprs, reserved))
+		}
 
-		bodyDepPkgs, bodyStmts := getBody(valueIdent, wrapFuncIdent, valueType, conf.OptionalInterfaces)
-		for _, pkg := range bodyDepPkgs {
-			importPkgMap[pkg.ID()] = pkg
+		if conf.BaseName != "" {
+			decls = append(decls, baseDecls(im, conf, reserved)...)
 		}
 
-		decls = append(decls, &ast.FuncDecl{
-			Name: ast.NewIdent(conf.FuncName),
-			Type: &ast.FuncType{
-				Params: &ast.FieldList{
-					List: []*ast.Field{{
-						Names: []*ast.Ident{
-							valueIdent,
-						},
-						Type: valueType,
-					}, {
-						Names: []*ast.Ident{
-							wrapFuncIdent,
-						},
-						Type: &ast.FuncType{
-							Params: &ast.FieldList{
-								List: []*ast.Field{{
-									Type: valueType,
-								}},
-							},
-							Results: &ast.FieldList{
-								List: []*ast.Field{{
-									Type: wrappedTypeExpr,
-								}},
-							},
-						},
-					}},
-				},
-				Results: &ast.FieldList{
-					List: []*ast.Field{{
-						Type: valueType,
-					}},
-				},
-			},
-			Body: &ast.BlockStmt{
-				List: bodyStmts,
-			},
-		})
+		// the variants of the wrapper function share the helpers of the embedder
+		embedder := newCaseEmbedder(lowerFirst(conf.WrappedInterface.name), conf.TypeParams, conf.WrappedInterface.interfaces)
+		wrapperDecls := []ast.Decl{
+			wrapperFuncDecl(im, conf, variantPlain, embedder, unwrapper, constIdents, optionalExprs, reserved),
+		}
+		if conf.WithCapabilitiesFuncName != "" {
+			wrapperDecls = append(wrapperDecls, wrapperFuncDecl(im, conf, variantCapabilities, embedder, unwrapper, constIdents, optionalExprs, reserved))
+		}
+		if conf.WithFilterFuncName != "" {
+			wrapperDecls = append(wrapperDecls, wrapperFuncDecl(im, conf, variantFilter, embedder, unwrapper, constIdents, optionalExprs, reserved))
+		}
+		if conf.WithValueFuncName != "" {
+			wrapperDecls = append(wrapperDecls, wrapperFuncDecl(im, conf, variantValue, embedder, unwrapper, constIdents, optionalExprs, reserved))
+		}
+
+		for _, helper := range embedder.helpers {
+			decls = append(decls, helper.Decl(im))
+		}
+		if conf.Hidden != nil {
+			for _, decl := range wrapperDecls {
+				docs[decl] = hideDoc(decl.(*ast.FuncDecl).Name.Name, conf.OptionalInterfaces, conf.Hidden)
+			}
+		}
+		decls = append(decls, wrapperDecls...)
+
+		if conf.Unwrap {
+			decls = append(decls, unwrapFuncDecl(im, conf, reserved))
+		}
+
+		for range decls[declNum:] {
+			owners = append(owners, conf)
+		}
 	}
 
-	importSpecs := make([]ast.Spec, 0, len(importPkgMap))
-	for _, pkg := range importPkgMap {
-		importSpecs = append(importSpecs, pkg.ImportSpec())
+	// declarations are printed separately, since the import declaration is written by the importer with its groups
+	var declBuf bytes.Buffer
+	for _, decl := range decls {
+		declBuf.WriteString("\n")
+		declBuf.WriteString(docs[decl])
+		if err := format.Node(&declBuf, fset, decl); err != nil {
+			return nil, fmt.Errorf("failed to format generated code: %w", err)
+		}
+		declBuf.WriteString("\n")
 	}
-	importDecl.Specs = importSpecs
 
-	_, err := io.WriteString(w, "// Code generated by iwrapper; DO NOT EDIT.\n")
+	var buf bytes.Buffer
+	buf.WriteString(generatedHeader)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	buf.WriteString(im.decl())
+	buf.Write(declBuf.Bytes())
+
+	src, err := format.Source(buf.Bytes())
 	if err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
 	}
 
-	f := ast.File{
-		Name:  ast.NewIdent(pkgName),
-		Decls: decls,
-	}
+	return &Output{
+		Src:    src,
+		owners: owners,
+	}, nil
+}
 
-	format.Node(w, fset, &f)
+// wrapperVariant is a variant of the wrapper function of the target.
+type wrapperVariant int
 
-	return nil
-}
+const (
+	// variantPlain passes only the value to the wrapper function given by the caller.
+	variantPlain wrapperVariant = iota
+	// variantCapabilities also passes the capabilities detected on the value to the wrapper function.
+	variantCapabilities
+	// variantFilter provides only the capabilities returned by the filter function given by the caller.
+	variantFilter
+	// variantValue takes the wrapper value instead of the wrapper function,
+	// as a convenience overload for the callers building the wrapper before the call.
+	variantValue
+)
 
-func getBody(valueIdent, wrapFuncIdent *ast.Ident, valueType ast.Expr, optionalInterfaces []*Interface) ([]*Package, []ast.Stmt) {
-	if len(optionalInterfaces) == 0 {
-		return nil, []ast.Stmt{&ast.ReturnStmt{
-			Results: []ast.Expr{valueIdent},
-		}}
+// wrapperFuncDecl returns the declaration of the variant of the wrapper function of the target.
+func wrapperFuncDecl(
+	im *importer,
+	conf *GenerateConfig,
+	variant wrapperVariant,
+	embedder *caseEmbedder,
+	unwrapper *ast.Field,
+	constIdents []*ast.Ident,
+	optionalExprs []ast.Expr,
+	reserved []string,
+) *ast.FuncDecl {
+	var (
+		valueIdent      = ast.NewIdent("v")
+		wrapFuncIdent   = ast.NewIdent("wrapper")
+		valueType       = conf.RequireInterface.Expr(im)
+		wrappedTypeExpr = conf.wrapperResultExpr(im)
+	)
+
+	funcName := conf.FuncName
+	typeParams := typeParamFieldList(im, conf.TypeParams)
+	wrapParams := []*ast.Field{{
+		Type: valueType,
+	}}
+	var (
+		filterIdent  *ast.Ident
+		filterParams []*ast.Field
+	)
+	switch variant {
+	case variantCapabilities:
+		funcName = conf.WithCapabilitiesFuncName
+		wrapParams = append(wrapParams, &ast.Field{
+			Type: ast.NewIdent(conf.CapabilityName),
+		})
+	case variantFilter:
+		funcName = conf.WithFilterFuncName
+		filterIdent = ast.NewIdent("filter")
+		filterParams = append(filterParams, &ast.Field{
+			Names: []*ast.Ident{filterIdent},
+			Type:  filterFuncType(conf),
+		})
+	case variantValue:
+		funcName = conf.WithValueFuncName
+		wrapFuncIdent = ast.NewIdent("w")
 	}
 
-	wrappedValueIdent := ast.NewIdent("wrapped")
-	indexIdent := ast.NewIdent("i")
-	bodyStmts := []ast.Stmt{
-		&ast.AssignStmt{
-			Tok: token.DEFINE,
-			Lhs: []ast.Expr{wrappedValueIdent},
-			Rhs: []ast.Expr{&ast.CallExpr{
-				Fun:  wrapFuncIdent,
-				Args: []ast.Expr{valueIdent},
+	var wrapperType ast.Expr = &ast.FuncType{
+		Params: &ast.FieldList{
+			List: wrapParams,
+		},
+		Results: &ast.FieldList{
+			List: []*ast.Field{{
+				Type: wrappedTypeExpr,
 			}},
 		},
-		&ast.DeclStmt{
-			Decl: &ast.GenDecl{
-				Tok: token.VAR,
-				Specs: []ast.Spec{&ast.ValueSpec{
-					Names: []*ast.Ident{indexIdent},
-					Type:  ast.NewIdent("uint64"),
+	}
+	if variant == variantValue {
+		wrapperType = wrappedTypeExpr
+	}
+
+	bodyStmts, locals, groups := getBody(im, valueIdent, wrapFuncIdent, filterIdent, embedder, conf, variant, unwrapper, constIdents, optionalExprs)
+
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent(funcName),
+		Type: &ast.FuncType{
+			TypeParams: typeParams,
+			Params: &ast.FieldList{
+				List: append([]*ast.Field{{
+					Names: []*ast.Ident{
+						valueIdent,
+					},
+					Type: valueType,
+				}, {
+					Names: []*ast.Ident{
+						wrapFuncIdent,
+					},
+					Type: wrapperType,
+				}}, filterParams...),
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{
+					Type: valueType,
 				}},
 			},
 		},
+		Body: &ast.BlockStmt{
+			List: bodyStmts,
+		},
 	}
+	params := []*ast.Ident{valueIdent, wrapFuncIdent}
+	if filterIdent != nil {
+		params = append(params, filterIdent)
+	}
+	renameLocals(funcDecl, reserved, append(params, locals...), groups...)
 
-	depPkgs := make([]*Package, 0, len(optionalInterfaces))
-	constSpecs := make([]ast.Spec, 0, len(optionalInterfaces))
-	checkStmts := make([]ast.Stmt, 0, len(optionalInterfaces))
-	optionalInterfaceExprs := make([]ast.Expr, 0, len(optionalInterfaces))
-	for i, intrfc := range optionalInterfaces {
-		ident := ast.NewIdent(fmt.Sprintf("i%d", i))
-		var values []ast.Expr
-		if i == 0 {
-			values = []ast.Expr{&ast.BinaryExpr{
-				X: &ast.BasicLit{
-					Kind:  token.INT,
-					Value: "1",
-				},
-				Op: token.SHL,
-				Y:  ast.NewIdent("iota"),
-			}}
-		}
+	return funcDecl
+}
 
-		constSpecs = append(constSpecs, &ast.ValueSpec{
-			Names:  []*ast.Ident{ident},
-			Values: values,
-		})
+// getBody returns the body of the wrapper function, with the identifiers of the local variables and the constants declared in it.
+// If unwrapper is not nil, it is embedded in the wrappers with the original value.
+// If filterIdent is not nil, only the capabilities returned by the filter function are provided.
+// In variantValue, wrapFuncIdent is the wrapper value instead of the wrapper function.
+// The optional interfaces are detected into the mask of the capability constants.
+func getBody(
+	im *importer,
+	valueIdent, wrapFuncIdent, filterIdent *ast.Ident,
+	embedder *caseEmbedder,
+	conf *GenerateConfig,
+	variant wrapperVariant,
+	unwrapper *ast.Field,
+	constIdents []*ast.Ident,
+	optionalExprs []ast.Expr,
+) ([]ast.Stmt, []*ast.Ident, []identGroup) {
+	var (
+		requireInterface   = conf.RequireInterface
+		optionalInterfaces = conf.OptionalInterfaces
+		providedInterfaces = conf.ProvidedInterfaces
+		casePlan           = conf.CasePlan
+	)
 
-		pkg, expr := intrfc.Expr()
-		depPkgs = append(depPkgs, pkg)
-		optionalInterfaceExprs = append(optionalInterfaceExprs, expr)
-
-		okIdent := ast.NewIdent("ok")
-		checkStmts = append(checkStmts, &ast.IfStmt{
-			Init: &ast.AssignStmt{
-				Lhs: []ast.Expr{ast.NewIdent("_"), okIdent},
-				Tok: token.DEFINE,
-				Rhs: []ast.Expr{&ast.TypeAssertExpr{
-					X:    valueIdent,
-					Type: expr,
-				}},
-			},
-			Cond: okIdent,
-			Body: &ast.BlockStmt{
-				List: []ast.Stmt{&ast.AssignStmt{
-					Lhs: []ast.Expr{indexIdent},
-					Tok: token.OR_ASSIGN,
-					Rhs: []ast.Expr{ident},
-				}},
-			},
-		})
+	if len(optionalInterfaces) == 0 && len(providedInterfaces) == 0 {
+		return []ast.Stmt{&ast.ReturnStmt{
+			Results: []ast.Expr{valueIdent},
+		}}, nil, nil
 	}
 
-	bodyStmts = append(bodyStmts, &ast.DeclStmt{
-		Decl: &ast.GenDecl{
-			Tok:   token.CONST,
-			Specs: constSpecs,
-		},
-	})
-	bodyStmts = append(bodyStmts, checkStmts...)
+	wrappedValueIdent := ast.NewIdent("wrapped")
+	indexIdent := ast.NewIdent("i")
+	okIdent := ast.NewIdent("ok")
+	implIdent := ast.NewIdent("impl")
+
+	// the wrapper function receives the capabilities detected before the call in the variant with capabilities
+	wrapArgs := []ast.Expr{valueIdent}
+	if variant == variantCapabilities {
+		wrapArgs = append(wrapArgs, indexIdent)
+	}
+	var wrapStmt ast.Stmt = &ast.AssignStmt{
+		Tok: token.DEFINE,
+		Lhs: []ast.Expr{wrappedValueIdent},
+		Rhs: []ast.Expr{&ast.CallExpr{
+			Fun:  wrapFuncIdent,
+			Args: wrapArgs,
+		}},
+	}
+	if variant == variantValue {
+		wrapStmt = wrapValueStmt(wrappedValueIdent, wrapFuncIdent)
+	}
 
-	caseClauseStmts := make([]ast.Stmt, 0, 1<<len(optionalInterfaces))
-	var i uint64 = 0
-	for ; i < 1<<len(optionalInterfaces); i++ {
-		typeFields := []*ast.Field{{
-			Type: valueType,
-		}}
-		elementsExprs := []ast.Expr{wrappedValueIdent}
+	// with the lookup or the delegation, the optional interfaces are provided by the capabilities instead of the wrapped value
+	var (
+		capIdents []*ast.Ident
+		usedCaps  []bool
+	)
+	if conf.LookupDepth > 0 || conf.Mode != ModeImplement {
+		capIdents = make([]*ast.Ident, 0, len(optionalInterfaces))
+		for range optionalInterfaces {
+			// the capabilities are named by renameLocals
+			capIdents = append(capIdents, ast.NewIdent(""))
+		}
+		usedCaps = make([]bool, len(optionalInterfaces))
+	}
+
+	masks := casePlan.Masks()
+	caseClauseStmts := make([]ast.Stmt, 0, len(masks))
+	for _, planMask := range masks {
+		mask := expandMask(planMask, conf.Hidden)
+		interfaces := make([]*Interface, 0, len(requireInterface.interfaces)+len(providedInterfaces)+len(optionalInterfaces))
+		interfaces = append(interfaces, requireInterface.interfaces...)
+		// the provided interfaces are embedded from the wrapped value in every case
+		interfaces = append(interfaces, providedInterfaces...)
+		// optionalIndexes are the indexes in the optional interfaces of the interfaces, -1 for the required and provided interfaces
+		optionalIndexes := make([]int, len(interfaces), cap(interfaces))
+		for j := range optionalIndexes {
+			optionalIndexes[j] = -1
+		}
 
-		tmpI := i
+		tmpMask := mask
 		for j := 0; j < len(optionalInterfaces); j++ {
-			if tmpI&1 != 0 {
-				typeFields = append(typeFields, &ast.Field{
-					Type: optionalInterfaceExprs[j],
-				})
-				elementsExprs = append(elementsExprs, wrappedValueIdent)
+			if tmpMask&1 != 0 {
+				interfaces = append(interfaces, optionalInterfaces[j])
+				optionalIndexes = append(optionalIndexes, j)
 			}
-			tmpI >>= 1
+			tmpMask >>= 1
 		}
 
-		caseClauseStmts = append(caseClauseStmts, &ast.CaseClause{
-			List: []ast.Expr{&ast.BasicLit{
+		embedded, sources := embedder.embed(interfaces)
+		typeFields := make([]*ast.Field, 0, len(embedded))
+		elementsExprs := make([]ast.Expr, 0, len(embedded))
+		for k, intrfc := range embedded {
+			typeFields = append(typeFields, &ast.Field{
+				Type: intrfc.Expr(im),
+			})
+
+			element := ast.Expr(wrappedValueIdent)
+			if j := optionalIndexes[sources[k]]; capIdents != nil && j >= 0 {
+				element = capIdents[j]
+				usedCaps[j] = true
+			}
+			elementsExprs = append(elementsExprs, element)
+		}
+		if unwrapper != nil {
+			typeFields = append(typeFields, unwrapper)
+			elementsExprs = append(elementsExprs, unwrapperLit(conf, valueIdent))
+		}
+
+		var caseList []ast.Expr
+		switch {
+		case casePlan.Exhaustive(), mask != 0 && casePlan.Fallback() == FallbackRequired:
+			caseList = []ast.Expr{&ast.BasicLit{
 				Kind:  token.INT,
-				Value: "0b" + strconv.FormatUint(i, 2),
-			}},
+				Value: "0b" + strconv.FormatUint(mask, 2),
+			}}
+		case mask != 0:
+			// the case matches when the value implements all optional interfaces of the mask
+			maskExpr := constMaskExpr(constIdents, mask)
+			caseList = []ast.Expr{&ast.BinaryExpr{
+				X: &ast.BinaryExpr{
+					X:  indexIdent,
+					Op: token.AND,
+					Y:  maskExpr,
+				},
+				Op: token.EQL,
+				Y:  maskExpr,
+			}}
+		default:
+			// the combination without optional interfaces is the fallback of all other combinations
+		}
+
+		caseClauseStmts = append(caseClauseStmts, &ast.CaseClause{
+			List: caseList,
 			Body: []ast.Stmt{&ast.ReturnStmt{
 				Results: []ast.Expr{&ast.CompositeLit{
 					Type: &ast.StructType{
@@ -234,14 +474,135 @@ func getBody(valueIdent, wrapFuncIdent *ast.Ident, valueType ast.Expr, optionalI
 		})
 	}
 
+	// without optional interfaces, the only case is returned without the detection
+	if len(optionalInterfaces) == 0 {
+		return []ast.Stmt{
+			wrapStmt,
+			caseClauseStmts[0].(*ast.CaseClause).Body[0],
+		}, []*ast.Ident{wrappedValueIdent}, nil
+	}
+
+	// the capabilities of the lookup are resolved by the wrapper function itself,
+	// and otherwise the detection is shared with the capabilities function
+	var bodyStmts []ast.Stmt
+	locals := []*ast.Ident{wrappedValueIdent, indexIdent, okIdent, implIdent}
+	if conf.LookupDepth > 0 {
+		detect, detectLocals := detectStmts(
+			conf, requireInterface.Expr(im),
+			valueIdent, indexIdent, okIdent,
+			constIdents, capIdents, optionalExprs, usedCaps,
+		)
+		bodyStmts = append(bodyStmts, detect...)
+		locals = append(locals, detectLocals...)
+	} else {
+		bodyStmts = append(bodyStmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{indexIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun:  instantiate(ast.NewIdent(conf.CapabilitiesFuncName), conf.TypeParams),
+				Args: []ast.Expr{valueIdent},
+			}},
+		})
+	}
+
+	// the capabilities are declared by the lookup if the target has it
+	if conf.LookupDepth == 0 {
+		if decl := capDeclStmt(capIdents, optionalExprs, usedCaps); decl != nil {
+			bodyStmts = append(bodyStmts, decl)
+		}
+	}
+
+	bodyStmts = append(bodyStmts, wrapStmt)
+
+	for j, used := range usedCaps {
+		if !used {
+			continue
+		}
+
+		if conf.intersects(j) {
+			bodyStmts = append(bodyStmts, intersectStmt(
+				wrappedValueIdent, indexIdent, implIdent, okIdent, constIdents[j], capIdents[j],
+				optionalExprs[j],
+			))
+			continue
+		}
+
+		if conf.Mode != ModeImplement {
+			bodyStmts = append(bodyStmts, delegateStmt(
+				conf.LookupDepth > 0,
+				valueIdent, wrappedValueIdent, implIdent, okIdent, capIdents[j],
+				optionalExprs[j],
+			))
+			continue
+		}
+
+		// the optional interfaces not found in the inner values are provided by the wrapped value
+		bodyStmts = append(bodyStmts, &ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X:  capIdents[j],
+				Op: token.EQL,
+				Y:  ast.NewIdent("nil"),
+			},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{&ast.AssignStmt{
+					Lhs: []ast.Expr{capIdents[j]},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{wrappedValueIdent},
+				}},
+			},
+		})
+	}
+
+	if filterIdent != nil {
+		bodyStmts = append(bodyStmts, filterStmt(indexIdent, filterIdent))
+	}
+
+	// the hidden interfaces are removed after the wrapper function receives the capabilities of the value
+	if conf.Hidden != nil {
+		bodyStmts = append(bodyStmts, hideStmt(indexIdent, constIdents, conf.Hidden))
+	}
+
+	var tag ast.Expr
+	if casePlan.Exhaustive() || casePlan.Fallback() == FallbackRequired {
+		tag = indexIdent
+	}
+
 	bodyStmts = append(bodyStmts, &ast.SwitchStmt{
-		Tag: indexIdent,
+		Tag: tag,
 		Body: &ast.BlockStmt{
 			List: caseClauseStmts,
 		},
-	}, &ast.ReturnStmt{
-		Results: []ast.Expr{valueIdent},
 	})
 
-	return depPkgs, bodyStmts
+	// the switch of a bounded plan has the default case
+	if casePlan.Exhaustive() {
+		bodyStmts = append(bodyStmts, &ast.ReturnStmt{
+			Results: []ast.Expr{valueIdent},
+		})
+	}
+
+	return bodyStmts, locals, []identGroup{{base: "c", idents: capIdents}}
+}
+
+// constMaskExpr returns the expression of the mask combining the constants of the optional interfaces.
+func constMaskExpr(constIdents []*ast.Ident, mask uint64) ast.Expr {
+	var expr ast.Expr
+	for i, ident := range constIdents {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+
+		if expr == nil {
+			expr = ident
+			continue
+		}
+
+		expr = &ast.BinaryExpr{
+			X:  expr,
+			Op: token.OR,
+			Y:  ident,
+		}
+	}
+
+	return expr
 }
@@ -0,0 +1,341 @@
+package iwrapper
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		target      string
+	}{{
+		description: "通常のtargetの生成コードがコンパイルできる",
+		target:      "normal.go",
+	}, {
+		description: "optionalが複数あっても生成コードがコンパイルできる",
+		target:      "multi_optional.go",
+	}, {
+		description: "requireが複数あっても生成コードがコンパイルできる",
+		target:      "multi_require.go",
+	}, {
+		description: "別fileで宣言したinterfaceでも生成コードがコンパイルできる",
+		target:      "other_file_declare.go",
+	}, {
+		description: "genericなtargetでも生成コードがコンパイルできる",
+		target:      "generic.go",
+	}, {
+		description: "inlineのmethodがあっても生成コードがコンパイルできる",
+		target:      "inline_method.go",
+	}, {
+		description: "methodが重複するinterfaceがあっても生成コードがコンパイルできる",
+		target:      "collision.go",
+	}, {
+		description: "max casesを超えるtargetでも生成コードがコンパイルできる",
+		target:      "many_optional.go",
+	}, {
+		description: "constraintがあっても生成コードがコンパイルできる",
+		target:      "constraints.go",
+	}, {
+		description: "observeで実在する組み合わせのみ生成できる",
+		target:      "observed.go",
+	}, {
+		description: "wrapでlocalなinterfaceなしに生成できる",
+		target:      "wrap.go",
+	}, {
+		description: "同名の別packageがあっても生成コードがコンパイルできる",
+		target:      "import_collision.go",
+	}, {
+		description: "生成コードの識別子がpackageやimportの名前と衝突しても生成コードがコンパイルできる",
+		target:      "hygiene/hygiene.go",
+	}, {
+		description: "unwrapを指定しても生成コードがコンパイルできる",
+		target:      "unwrap.go",
+	}, {
+		description: "lookupを指定しても生成コードがコンパイルできる",
+		target:      "lookup.go",
+	}, {
+		description: "capabilitiesを指定しても生成コードがコンパイルできる",
+		target:      "capabilities.go",
+	}, {
+		description: "baseを指定しても生成コードがコンパイルできる",
+		target:      "base.go",
+	}, {
+		description: "delegateモードでも生成コードがコンパイルできる",
+		target:      "delegate.go",
+	}, {
+		description: "intersectを指定しても生成コードがコンパイルできる",
+		target:      "intersect.go",
+	}, {
+		description: "provideを指定しても生成コードがコンパイルできる",
+		target:      "provide.go",
+	}, {
+		description: "hideを指定しても生成コードがコンパイルできる",
+		target:      "hide.go",
+	}, {
+		description: "filterを指定しても生成コードがコンパイルできる",
+		target:      "filter.go",
+	}, {
+		description: "valueを指定しても生成コードがコンパイルできる",
+		target:      "value.go",
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			src := filepath.Join("testdata", testCase.target)
+			generated := generateForTest(t, src)
+			checkGenerated(t, src, generated)
+		})
+	}
+}
+
+func generateForTest(t *testing.T, src string) *Output {
+	t.Helper()
+
+	pkgName, results, err := ParseTarget(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confs, err := Convert(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Render(pkgName, confs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return output
+}
+
+// behaviorPrelude declares the interfaces and the values wrapped in the tests of the behavior of the generated code.
+// Each method returns the name of the value receiving the call.
+const behaviorPrelude = `package behavior
+
+type Writer interface {
+	Write() string
+}
+
+type Flusher interface {
+	Flush() string
+}
+
+type Closer interface {
+	Close() string
+}
+
+type writer struct {
+	name string
+}
+
+func (w writer) Write() string { return w.name + ".Write" }
+
+type flushWriter struct {
+	writer
+}
+
+func (w flushWriter) Flush() string { return w.name + ".Flush" }
+
+type closeWriter struct {
+	writer
+}
+
+func (w closeWriter) Close() string { return w.name + ".Close" }
+
+type fullWriter struct {
+	writer
+}
+
+func (w fullWriter) Flush() string { return w.name + ".Flush" }
+
+func (w fullWriter) Close() string { return w.name + ".Close" }
+
+// unwrapWriter implements only Writer, and unwraps to the inner value.
+type unwrapWriter struct {
+	writer
+	inner Writer
+}
+
+func (w unwrapWriter) Unwrap() Writer { return w.inner }
+`
+
+// runGenerated generates the wrappers of the targets declared after behaviorPrelude,
+// and runs the test file using them in a temporary module.
+func runGenerated(t *testing.T, targets, test string) {
+	t.Helper()
+
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module behavior\n\ngo 1.25\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(dir, "target.go")
+	if err := os.WriteFile(src, []byte(behaviorPrelude+"\n"+targets), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := generateForTest(t, src)
+	if err := os.WriteFile(filepath.Join(dir, "iwrapper_target.go"), output.Src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "behavior_test.go"), []byte(test), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goCmd, "test", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("generated code behaves unexpectedly: %v\n%s\n%s", err, out, output.Src)
+	}
+}
+
+// checkGenerated type-checks the generated code together with the package containing src.
+func checkGenerated(t *testing.T, src string, output *Output) {
+	t.Helper()
+
+	dst := filepath.Join(filepath.Dir(src), "iwrapper_test_generated.go")
+	if err := output.Check(dst); err != nil {
+		t.Errorf("type error in generated code: %v\n%s", err, output.Src)
+	}
+}
+
+func TestBaseProvided(t *testing.T) {
+	t.Parallel()
+
+	output := generateForTest(t, filepath.Join("testdata", "base.go"))
+
+	// the original value may not implement the provided interfaces, so the wrapper must implement them itself
+	if bytes.Contains(output.Src, []byte("func (b PassthroughProvideBase) Flush()")) {
+		t.Errorf("base forwards the provided interface:\n%s", output.Src)
+	}
+	if !bytes.Contains(output.Src, []byte("func (b PassthroughProvideBase) Hijack()")) {
+		t.Errorf("base does not forward the optional interface:\n%s", output.Src)
+	}
+}
+
+func TestRenderDeterministic(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join("testdata", "import_collision.go")
+	expected := generateForTest(t, src)
+	for range 10 {
+		if actual := generateForTest(t, src); !bytes.Equal(expected.Src, actual.Src) {
+			t.Fatalf("generated code differs:\n%s", UnifiedDiff("iwrapper_test_generated.go", expected.Src, actual.Src))
+		}
+	}
+}
+
+// BenchmarkGenerate reports the size of the generated file and the time to build it
+// as the number of optional interfaces grows.
+func BenchmarkGenerate(b *testing.B) {
+	benchCases := []struct {
+		optionalNum int
+		maxCases    int
+	}{
+		{optionalNum: 2, maxCases: DefaultMaxCases},
+		{optionalNum: 4, maxCases: DefaultMaxCases},
+		{optionalNum: 6, maxCases: DefaultMaxCases},
+		{optionalNum: 8, maxCases: DefaultMaxCases},
+		{optionalNum: 10, maxCases: DefaultMaxCases},
+		{optionalNum: 10, maxCases: 1 << 10},
+		{optionalNum: 12, maxCases: DefaultMaxCases},
+		{optionalNum: 16, maxCases: DefaultMaxCases},
+		{optionalNum: 32, maxCases: DefaultMaxCases},
+	}
+
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		b.Skip("go command not found")
+	}
+
+	for _, benchCase := range benchCases {
+		b.Run(fmt.Sprintf("optional=%d/cases=%d", benchCase.optionalNum, benchCase.maxCases), func(b *testing.B) {
+			dir := b.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module bench\n\ngo 1.25\n"), 0o644); err != nil {
+				b.Fatal(err)
+			}
+
+			src := filepath.Join(dir, "target.go")
+			if err := os.WriteFile(src, benchTarget(benchCase.optionalNum, benchCase.maxCases), 0o644); err != nil {
+				b.Fatal(err)
+			}
+
+			pkgName, results, err := ParseTarget(src)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			confs, err := Convert(results)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var (
+				size      int
+				buildTime time.Duration
+				n         int
+			)
+			for b.Loop() {
+				var buf bytes.Buffer
+				if err := Generate(&buf, pkgName, confs); err != nil {
+					b.Fatal(err)
+				}
+				size = buf.Len()
+
+				// change the content every time to avoid the build cache
+				fmt.Fprintf(&buf, "\nconst benchIteration = %d\n", n)
+				if err := os.WriteFile(filepath.Join(dir, "iwrapper_target.go"), buf.Bytes(), 0o644); err != nil {
+					b.Fatal(err)
+				}
+
+				cmd := exec.Command(goCmd, "build", "./...")
+				cmd.Dir = dir
+				start := time.Now()
+				if out, err := cmd.CombinedOutput(); err != nil {
+					b.Fatalf("failed to build: %v\n%s", err, out)
+				}
+				buildTime += time.Since(start)
+				n++
+			}
+
+			b.ReportMetric(float64(size), "bytes/file")
+			b.ReportMetric(buildTime.Seconds()/float64(n), "build-sec/op")
+		})
+	}
+}
+
+func benchTarget(optionalNum, maxCases int) []byte {
+	var sb strings.Builder
+	sb.WriteString("package bench\n\n")
+	sb.WriteString("type Required interface {\n\tRequiredMethod()\n}\n\n")
+	for i := range optionalNum {
+		fmt.Fprintf(&sb, "type Optional%d interface {\n\tOptionalMethod%d()\n}\n\n", i, i)
+	}
+
+	fmt.Fprintf(&sb, "//iwrapper:target cases:\"%d\"\n", maxCases)
+	sb.WriteString("type Target interface {\n\t//iwrapper:require\n\tRequired\n")
+	for i := range optionalNum {
+		fmt.Fprintf(&sb, "\tOptional%d\n", i)
+	}
+	sb.WriteString("}\n")
+
+	return []byte(sb.String())
+}
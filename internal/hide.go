@@ -0,0 +1,148 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidHide = errors.New("invalid hide")
+)
+
+// hiddenInterfaces returns the optional interfaces with the hide directive.
+// The hidden interfaces stay in the target and the capabilities, but the wrappers never provide them.
+//
+//	//iwrapper:hide
+func hiddenInterfaces(elements []*element) ([]*Interface, error) {
+	var hidden []*Interface
+	for _, elem := range elements {
+		if elem.doc == nil {
+			continue
+		}
+
+		for _, comment := range elem.doc.List {
+			args, ok := directiveArgs(comment.Text, hideDirectivePrefix)
+			if !ok {
+				continue
+			}
+
+			if len(args) != 0 {
+				return nil, fmt.Errorf("%w: %s takes no arguments", ErrInvalidHide, hideDirectivePrefix)
+			}
+			if isRequired(elem.doc) {
+				return nil, fmt.Errorf("%w: required interface(%s) can not be hidden", ErrInvalidHide, elem.intrfc)
+			}
+			if isProvided(elem.doc) {
+				return nil, fmt.Errorf("%w: provided interface(%s) can not be hidden", ErrInvalidHide, elem.intrfc)
+			}
+
+			hidden = append(hidden, elem.intrfc)
+		}
+	}
+
+	return hidden, nil
+}
+
+// shownInterfaces returns the optional interfaces which are not hidden,
+// and the constraints not referring to the hidden interfaces.
+// The constraints on the hidden interfaces only reduce the cases, so dropping them keeps the wrappers correct.
+func shownInterfaces(optionalInterfaces, hidden []*Interface, constraints []*Constraint) ([]*Interface, []*Constraint) {
+	if len(hidden) == 0 {
+		return optionalInterfaces, constraints
+	}
+
+	shown := make([]*Interface, 0, len(optionalInterfaces))
+	for _, intrfc := range optionalInterfaces {
+		if !slices.Contains(hidden, intrfc) {
+			shown = append(shown, intrfc)
+		}
+	}
+
+	shownConstraints := make([]*Constraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		if !slices.ContainsFunc(constraint.Interfaces, func(intrfc *Interface) bool {
+			return slices.Contains(hidden, intrfc)
+		}) {
+			shownConstraints = append(shownConstraints, constraint)
+		}
+	}
+
+	return shown, shownConstraints
+}
+
+// compressMask returns the mask of the optional interfaces which are not hidden,
+// removing the bits of the hidden interfaces from the mask of all optional interfaces.
+func compressMask(mask uint64, hidden []bool) uint64 {
+	var compressed uint64
+	var shift int
+	for j, isHidden := range hidden {
+		if isHidden {
+			continue
+		}
+
+		compressed |= (mask >> j & 1) << shift
+		shift++
+	}
+
+	return compressed
+}
+
+// expandMask is the inverse of compressMask, which spreads the mask of the optional interfaces which are not hidden
+// over the bits of all optional interfaces.
+func expandMask(mask uint64, hidden []bool) uint64 {
+	if hidden == nil {
+		return mask
+	}
+
+	var expanded uint64
+	var shift int
+	for j, isHidden := range hidden {
+		if isHidden {
+			continue
+		}
+
+		expanded |= (mask >> shift & 1) << j
+		shift++
+	}
+
+	return expanded
+}
+
+// hideStmt returns the statement removing the hidden interfaces from the capabilities:
+//
+//	i &^= ResponseWriterCloseNotifier
+func hideStmt(indexIdent *ast.Ident, constIdents []*ast.Ident, hidden []bool) ast.Stmt {
+	var mask uint64
+	for j, isHidden := range hidden {
+		if isHidden {
+			mask |= 1 << j
+		}
+	}
+
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{indexIdent},
+		Tok: token.AND_NOT_ASSIGN,
+		Rhs: []ast.Expr{constMaskExpr(constIdents, mask)},
+	}
+}
+
+// hideDoc returns the doc comment of the wrapper function documenting the hidden interfaces.
+func hideDoc(funcName string, optionalInterfaces []*Interface, hidden []bool) string {
+	var names []string
+	for j, isHidden := range hidden {
+		if isHidden {
+			names = append(names, capabilityLabel(optionalInterfaces[j]))
+		}
+	}
+
+	verb := "is"
+	if len(names) > 1 {
+		verb = "are"
+	}
+
+	return fmt.Sprintf("// %s never provides %s, which %s hidden on purpose.\n", funcName, strings.Join(names, ", "), verb)
+}
@@ -0,0 +1,82 @@
+package iwrapper
+
+import "testing"
+
+func TestHiddenMask(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		hidden      []bool
+		mask        uint64
+		compressed  uint64
+	}{{
+		description: "hideがなければそのまま",
+		hidden:      nil,
+		mask:        0b101,
+		compressed:  0b101,
+	}, {
+		description: "hideしたinterfaceのbitを詰める",
+		hidden:      []bool{false, true, false, true},
+		mask:        0b0101,
+		compressed:  0b11,
+	}, {
+		description: "先頭のinterfaceをhideできる",
+		hidden:      []bool{true, false, false},
+		mask:        0b110,
+		compressed:  0b11,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			if testCase.hidden != nil {
+				if compressed := compressMask(testCase.mask, testCase.hidden); compressed != testCase.compressed {
+					t.Errorf("compressed: expected %b, got %b", testCase.compressed, compressed)
+				}
+			}
+
+			if expanded := expandMask(testCase.compressed, testCase.hidden); expanded != testCase.mask {
+				t.Errorf("expanded: expected %b, got %b", testCase.mask, expanded)
+			}
+		})
+	}
+}
+
+func TestHideBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	//iwrapper:hide
+	Closer
+}
+`, `package behavior
+
+import "testing"
+
+func TestHide(t *testing.T) {
+	v := fullWriter{writer{"orig"}}
+	w := TargetWrapper(v, func(Writer) Target {
+		return fullWriter{writer{"wrapper"}}
+	})
+
+	if _, ok := w.(Closer); ok {
+		t.Error("hidden Closer is provided")
+	}
+	if f, ok := w.(Flusher); !ok || f.Flush() != "wrapper.Flush" {
+		t.Error("Flusher is not provided by the wrapped value")
+	}
+
+	// the capabilities still report the hidden interface of the original value
+	if c := TargetCapabilities(v); c != TargetFlusher|TargetCloser {
+		t.Errorf("capabilities: expected %s, got %s", TargetFlusher|TargetCloser, c)
+	}
+}
+`)
+}
@@ -0,0 +1,156 @@
+package iwrapper
+
+import (
+	"cmp"
+	"fmt"
+	"go/types"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// importer assigns the names the generated file refers to the imported packages with.
+// Packages are keyed by import path, and packages whose names clash get unique aliases.
+type importer struct {
+	pkgs map[string]*Package
+	// names maps the import path to the name of the package in the generated file
+	names map[string]string
+	// paths maps the name of the package in the generated file to the import path
+	paths map[string]string
+}
+
+func newImporter() *importer {
+	return &importer{
+		pkgs:  map[string]*Package{},
+		names: map[string]string{},
+		paths: map[string]string{},
+	}
+}
+
+// reserve marks the names as not available for the packages, such as the names declared in the package of the generated file.
+func (im *importer) reserve(names ...string) {
+	for _, name := range names {
+		if _, ok := im.paths[name]; !ok {
+			im.paths[name] = ""
+		}
+	}
+}
+
+// name imports the package and returns the name referring to it.
+func (im *importer) name(pkg *Package) string {
+	if name, ok := im.names[pkg.path]; ok {
+		return name
+	}
+
+	name := im.uniqueName(pkg)
+	im.pkgs[pkg.path] = pkg
+	im.names[pkg.path] = name
+	im.paths[name] = pkg.path
+
+	return name
+}
+
+// uniqueName returns the first name not taken among the package name,
+// the names prefixing the parent elements of the import path (e.g. mathrand for math/rand) and numbered names.
+func (im *importer) uniqueName(pkg *Package) string {
+	if _, ok := im.paths[pkg.name]; !ok {
+		return pkg.name
+	}
+
+	// the last element and the major version suffix (e.g. math/rand/v2) are already represented by the package name
+	elements := strings.Split(pkg.path, "/")
+	elements = elements[:len(elements)-1]
+	if len(elements) != 0 && elements[len(elements)-1] == pkg.name {
+		elements = elements[:len(elements)-1]
+	}
+
+	for i := len(elements) - 1; i >= 0; i-- {
+		name := sanitizeName(strings.Join(elements[i:], "") + pkg.name)
+		if _, ok := im.paths[name]; !ok && name != "" {
+			return name
+		}
+	}
+
+	for i := 2; ; i++ {
+		name := pkg.name + strconv.Itoa(i)
+		if _, ok := im.paths[name]; !ok {
+			return name
+		}
+	}
+}
+
+// sanitizeName drops the characters not allowed in identifiers, such as dots and hyphens of import paths.
+func sanitizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || r == '_' || unicode.IsDigit(r) && sb.Len() != 0 {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return sb.String()
+}
+
+// qualifier returns the qualifier of types.TypeString, importing the packages other than the local package.
+func (im *importer) qualifier(localPath string) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg.Path() == localPath {
+			return ""
+		}
+
+		return im.name(newPackageFromTypes(pkg))
+	}
+}
+
+// decl returns the source of the import declaration, sorted by path and grouped into the standard library and the others
+// in the same way as goimports. It returns an empty string if no package is imported.
+func (im *importer) decl() string {
+	if len(im.pkgs) == 0 {
+		return ""
+	}
+
+	var std, others []string
+	for _, importPath := range slices.SortedFunc(maps.Keys(im.pkgs), cmp.Compare[string]) {
+		pkg := im.pkgs[importPath]
+
+		spec := strconv.Quote(importPath)
+		// the name is written if it differs from the last element of the path
+		if name := im.names[importPath]; name != pkg.name || pkg.name != path.Base(importPath) {
+			spec = name + " " + spec
+		}
+
+		if isStdPath(importPath) {
+			std = append(std, spec)
+		} else {
+			others = append(others, spec)
+		}
+	}
+
+	if len(im.pkgs) == 1 {
+		return "import " + slices.Concat(std, others)[0] + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("import (\n")
+	for i, group := range [][]string{std, others} {
+		if i != 0 && len(std) != 0 && len(group) != 0 {
+			sb.WriteString("\n")
+		}
+		for _, spec := range group {
+			fmt.Fprintf(&sb, "\t%s\n", spec)
+		}
+	}
+	sb.WriteString(")\n")
+
+	return sb.String()
+}
+
+// isStdPath reports whether the import path is of the standard library, which has no dot in the first element.
+func isStdPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+
+	return !strings.Contains(first, ".")
+}
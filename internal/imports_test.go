@@ -0,0 +1,96 @@
+package iwrapper
+
+import "testing"
+
+func TestImporter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description   string
+		reserved      []string
+		pkgs          []*Package
+		expectedNames []string
+		expectedDecl  string
+	}{{
+		description:   "importがなければ宣言しない",
+		expectedNames: []string{},
+		expectedDecl:  "",
+	}, {
+		description: "import pathでソートされ、標準ライブラリとそれ以外に分けられる",
+		pkgs: []*Package{
+			NewPackage("cmp", "github.com/google/go-cmp/cmp"),
+			NewPackage("http", "net/http"),
+			NewPackage("io", "io"),
+		},
+		expectedNames: []string{"cmp", "http", "io"},
+		expectedDecl:  "import (\n\t\"io\"\n\t\"net/http\"\n\n\t\"github.com/google/go-cmp/cmp\"\n)\n",
+	}, {
+		description: "同じpackageは1度だけimportされる",
+		pkgs: []*Package{
+			NewPackage("io", "io"),
+			NewPackage("io", "io"),
+		},
+		expectedNames: []string{"io", "io"},
+		expectedDecl:  "import \"io\"\n",
+	}, {
+		description: "名前が衝突するpackageには一意なaliasが付けられる",
+		pkgs: []*Package{
+			NewPackage("rand", "math/rand"),
+			NewPackage("rand", "crypto/rand"),
+			NewPackage("rand", "math/rand/v2"),
+			NewPackage("http", "example.com/foo/http"),
+			NewPackage("http", "example.com/bar/http"),
+			NewPackage("http", "net/http"),
+		},
+		expectedNames: []string{"rand", "cryptorand", "mathrand", "http", "barhttp", "nethttp"},
+		expectedDecl: "import (\n\tcryptorand \"crypto/rand\"\n\t\"math/rand\"\n\tmathrand \"math/rand/v2\"\n\tnethttp \"net/http\"\n\n" +
+			"\tbarhttp \"example.com/bar/http\"\n\t\"example.com/foo/http\"\n)\n",
+	}, {
+		description: "予約された名前はaliasで避けられる",
+		reserved:    []string{"http"},
+		pkgs: []*Package{
+			NewPackage("http", "net/http"),
+		},
+		expectedNames: []string{"nethttp"},
+		expectedDecl:  "import nethttp \"net/http\"\n",
+	}, {
+		description: "package名がpathの末尾と異なる場合はaliasを付ける",
+		pkgs: []*Package{
+			NewPackage("yaml", "gopkg.in/yaml.v3"),
+		},
+		expectedNames: []string{"yaml"},
+		expectedDecl:  "import yaml \"gopkg.in/yaml.v3\"\n",
+	}, {
+		description: "aliasが全て使われていれば番号を付ける",
+		pkgs: []*Package{
+			NewPackage("rand", "rand"),
+			NewPackage("rand", "example.com/rand"),
+			NewPackage("examplecomrand", "examplecomrand"),
+			NewPackage("rand", "example.com/rand/v2"),
+		},
+		expectedNames: []string{"rand", "examplecomrand", "examplecomrand2", "rand2"},
+		expectedDecl: "import (\n\texamplecomrand2 \"examplecomrand\"\n\t\"rand\"\n\n" +
+			"\texamplecomrand \"example.com/rand\"\n\trand2 \"example.com/rand/v2\"\n)\n",
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			im := newImporter()
+			im.reserve(testCase.reserved...)
+			names := make([]string, 0, len(testCase.pkgs))
+			for _, pkg := range testCase.pkgs {
+				names = append(names, im.name(pkg))
+			}
+
+			if diff := diff(testCase.expectedNames, names); diff != "" {
+				t.Errorf("names: (-expected +actual)\n%s", diff)
+			}
+
+			if decl := im.decl(); decl != testCase.expectedDecl {
+				t.Errorf("decl: expected %q, got %q", testCase.expectedDecl, decl)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package iwrapper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedSyntax |
+	packages.NeedTypes |
+	packages.NeedTypesInfo |
+	packages.NeedImports
+
+var (
+	ErrNoPackages = errors.New("no packages matched")
+)
+
+func LoadPackages(patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: loadMode,
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	if len(pkgs) == 0 {
+		return nil, ErrNoPackages
+	}
+
+	if err := checkPackageErrors(pkgs); err != nil {
+		return nil, err
+	}
+
+	return pkgs, nil
+}
+
+func checkPackageErrors(pkgs []*packages.Package) error {
+	var errs []error
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			// type errors are tolerated because stale generated files may not compile.
+			// elements which can not be resolved are reported when the target is parsed.
+			if pkgErr.Kind != packages.ParseError && len(pkg.Syntax) != 0 {
+				continue
+			}
+
+			pos := packageErrorPosition(pkgErr)
+			// broken generated files are tolerated because they are regenerated.
+			if pkgErr.Kind != packages.ListError && IsGeneratedFile(pos.Filename) {
+				continue
+			}
+
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("failed to load package(%s): %s", pkg.PkgPath, pkgErr.Msg)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsGeneratedFile reports whether the file is generated by iwrapper, including the empty files left by failed runs.
+func IsGeneratedFile(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return len(bytes.TrimSpace(src)) == 0 || bytes.HasPrefix(src, []byte(generatedHeader))
+}
+
+// PackageDir returns the directory containing the Go files of the package.
+func PackageDir(pkg *packages.Package) (string, error) {
+	if len(pkg.GoFiles) == 0 {
+		return "", fmt.Errorf("package(%s) has no go files", pkg.PkgPath)
+	}
+
+	return filepath.Dir(pkg.GoFiles[0]), nil
+}
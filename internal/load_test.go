@@ -0,0 +1,50 @@
+package iwrapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPackagesBrokenGeneratedFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		generated   string
+		isErr       bool
+	}{{
+		description: "空の生成ファイルは無視する",
+		generated:   "",
+	}, {
+		description: "途中まで書かれた生成ファイルは無視する",
+		generated:   generatedHeader + "package broken\n\nfunc",
+	}, {
+		description: "生成ファイルでないファイルの構文エラーはエラー",
+		generated:   "package broken\n\nfunc",
+		isErr:       true,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			files := map[string]string{
+				"go.mod":          "module example.com/broken\n\ngo 1.25\n",
+				"broken.go":       "package broken\n",
+				"iwrapper_gen.go": testCase.generated,
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, _, err := ParseTarget(filepath.Join(dir, "broken.go"))
+			if testCase.isErr != (err != nil) {
+				t.Errorf("error: expected error %t, got %v", testCase.isErr, err)
+			}
+		})
+	}
+}
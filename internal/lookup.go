@@ -0,0 +1,228 @@
+package iwrapper
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+const (
+	// lookupUnwrap is the lookup of the optional interfaces through the Unwrap chain of the value.
+	lookupUnwrap = "unwrap"
+	// DefaultLookupDepth is the default maximum number of Unwrap calls to look up the optional interfaces.
+	DefaultLookupDepth = 16
+)
+
+var (
+	ErrInvalidLookup = errors.New("invalid lookup")
+)
+
+// lookupStmts returns the statements looking up the optional interfaces the value does not implement
+// through the Unwrap chain of the value, up to the depth.
+// The capabilities are the variables holding the inner values implementing the optional interfaces,
+// which are nil if the value itself implements them or no inner value does.
+// Only the optional interfaces whose capabilities are used get the variables.
+//
+//	var c0 http.Flusher
+//	inner := v
+//	for depth := 0; depth < 16; depth++ {
+//		unwrapper, ok := inner.(interface{ Unwrap() http.ResponseWriter })
+//		if !ok {
+//			break
+//		}
+//		inner = unwrapper.Unwrap()
+//		if i&ResponseWriterFlusher == 0 {
+//			if found, ok := inner.(http.Flusher); ok {
+//				i |= ResponseWriterFlusher
+//				c0 = found
+//			}
+//		}
+//	}
+func lookupStmts(
+	depth int,
+	valueType ast.Expr,
+	valueIdent, indexIdent, okIdent *ast.Ident,
+	constIdents, capIdents []*ast.Ident,
+	optionalExprs []ast.Expr,
+	usedCaps []bool,
+) ([]ast.Stmt, []*ast.Ident) {
+	var (
+		innerIdent     = ast.NewIdent("inner")
+		depthIdent     = ast.NewIdent("depth")
+		unwrapperIdent = ast.NewIdent("unwrapper")
+		foundIdent     = ast.NewIdent("found")
+	)
+
+	loopStmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{unwrapperIdent, okIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.TypeAssertExpr{
+				X:    innerIdent,
+				Type: unwrapInterfaceExpr(valueType),
+			}},
+		},
+		&ast.IfStmt{
+			Cond: &ast.UnaryExpr{
+				Op: token.NOT,
+				X:  okIdent,
+			},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{&ast.BranchStmt{
+					Tok: token.BREAK,
+				}},
+			},
+		},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{innerIdent},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   unwrapperIdent,
+					Sel: ast.NewIdent(unwrapMethodName),
+				},
+			}},
+		},
+	}
+
+	for j, expr := range optionalExprs {
+		foundExpr := ast.Expr(ast.NewIdent("_"))
+		foundStmts := []ast.Stmt{&ast.AssignStmt{
+			Lhs: []ast.Expr{indexIdent},
+			Tok: token.OR_ASSIGN,
+			Rhs: []ast.Expr{constIdents[j]},
+		}}
+		if usedCaps[j] {
+			foundExpr = foundIdent
+			foundStmts = append(foundStmts, &ast.AssignStmt{
+				Lhs: []ast.Expr{capIdents[j]},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{foundIdent},
+			})
+		}
+
+		loopStmts = append(loopStmts, &ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				X: &ast.BinaryExpr{
+					X:  indexIdent,
+					Op: token.AND,
+					Y:  constIdents[j],
+				},
+				Op: token.EQL,
+				Y: &ast.BasicLit{
+					Kind:  token.INT,
+					Value: "0",
+				},
+			},
+			Body: &ast.BlockStmt{
+				List: []ast.Stmt{&ast.IfStmt{
+					Init: &ast.AssignStmt{
+						Lhs: []ast.Expr{foundExpr, okIdent},
+						Tok: token.DEFINE,
+						Rhs: []ast.Expr{&ast.TypeAssertExpr{
+							X:    innerIdent,
+							Type: expr,
+						}},
+					},
+					Cond: okIdent,
+					Body: &ast.BlockStmt{
+						List: foundStmts,
+					},
+				}},
+			},
+		})
+	}
+
+	var stmts []ast.Stmt
+	if decl := capDeclStmt(capIdents, optionalExprs, usedCaps); decl != nil {
+		stmts = append(stmts, decl)
+	}
+
+	stmts = append(stmts,
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{innerIdent},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{valueIdent},
+		},
+		&ast.ForStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{depthIdent},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.BasicLit{
+					Kind:  token.INT,
+					Value: "0",
+				}},
+			},
+			Cond: &ast.BinaryExpr{
+				X:  depthIdent,
+				Op: token.LSS,
+				Y: &ast.BasicLit{
+					Kind:  token.INT,
+					Value: strconv.Itoa(depth),
+				},
+			},
+			Post: &ast.IncDecStmt{
+				X:   depthIdent,
+				Tok: token.INC,
+			},
+			Body: &ast.BlockStmt{
+				List: loopStmts,
+			},
+		},
+	)
+
+	return stmts, []*ast.Ident{innerIdent, depthIdent, unwrapperIdent, foundIdent}
+}
+
+// unwrapInterfaceExpr returns the interface of the values unwrapped to the type:
+//
+//	interface{ Unwrap() http.ResponseWriter }
+func unwrapInterfaceExpr(valueType ast.Expr) ast.Expr {
+	return &ast.InterfaceType{
+		Methods: &ast.FieldList{
+			List: []*ast.Field{{
+				Names: []*ast.Ident{ast.NewIdent(unwrapMethodName)},
+				Type: &ast.FuncType{
+					Params: &ast.FieldList{},
+					Results: &ast.FieldList{
+						List: []*ast.Field{{
+							Type: valueType,
+						}},
+					},
+				},
+			}},
+		},
+	}
+}
+
+// capDeclStmt returns the declaration of the used capabilities, or nil if no capability is used:
+//
+//	var (
+//		c0 http.Flusher
+//		c1 io.ReaderFrom
+//	)
+func capDeclStmt(capIdents []*ast.Ident, optionalExprs []ast.Expr, usedCaps []bool) ast.Stmt {
+	var specs []ast.Spec
+	for j, used := range usedCaps {
+		if !used {
+			continue
+		}
+
+		specs = append(specs, &ast.ValueSpec{
+			Names: []*ast.Ident{capIdents[j]},
+			Type:  optionalExprs[j],
+		})
+	}
+
+	if len(specs) == 0 {
+		return nil
+	}
+
+	return &ast.DeclStmt{
+		Decl: &ast.GenDecl{
+			Tok:   token.VAR,
+			Specs: specs,
+		},
+	}
+}
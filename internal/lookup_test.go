@@ -0,0 +1,84 @@
+package iwrapper
+
+import "testing"
+
+func TestLookupBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target lookup:"unwrap"
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	Closer
+}
+
+//iwrapper:target lookup:"unwrap" depth:"1"
+type Shallow interface {
+	//iwrapper:require
+	Writer
+	Flusher
+	Closer
+}
+`, `package behavior
+
+import "testing"
+
+func wrapper(Writer) Target {
+	return fullWriter{writer{"wrapper"}}
+}
+
+func TestLookup(t *testing.T) {
+	v := unwrapWriter{writer{"outer"}, unwrapWriter{writer{"middle"}, flushWriter{writer{"inner"}}}}
+	w := TargetWrapper(v, wrapper)
+
+	if got := w.Write(); got != "wrapper.Write" {
+		t.Errorf("Write: expected wrapper.Write, got %s", got)
+	}
+
+	// the interface found through the Unwrap chain is provided by the inner value implementing it
+	f, ok := w.(Flusher)
+	if !ok {
+		t.Fatal("Flusher of the inner value is not found")
+	}
+	if got := f.Flush(); got != "inner.Flush" {
+		t.Errorf("Flush: expected inner.Flush, got %s", got)
+	}
+
+	if _, ok := w.(Closer); ok {
+		t.Error("Closer is provided although no value in the chain implements it")
+	}
+
+	if c := TargetCapabilities(v); c != TargetFlusher {
+		t.Errorf("capabilities: expected %s, got %s", TargetFlusher, c)
+	}
+}
+
+func TestLookupOwnValue(t *testing.T) {
+	v := flushWriter{writer{"outer"}}
+	w := TargetWrapper(v, wrapper)
+
+	// the interface implemented by the value itself is provided by the wrapped value
+	f, ok := w.(Flusher)
+	if !ok {
+		t.Fatal("Flusher of the value is not provided")
+	}
+	if got := f.Flush(); got != "wrapper.Flush" {
+		t.Errorf("Flush: expected wrapper.Flush, got %s", got)
+	}
+}
+
+func TestLookupDepth(t *testing.T) {
+	v := unwrapWriter{writer{"outer"}, unwrapWriter{writer{"middle"}, flushWriter{writer{"inner"}}}}
+	w := ShallowWrapper(v, func(Writer) Shallow {
+		return fullWriter{writer{"wrapper"}}
+	})
+
+	// the inner value is beyond the depth
+	if _, ok := w.(Flusher); ok {
+		t.Error("Flusher beyond the depth is found")
+	}
+}
+`)
+}
@@ -0,0 +1,253 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+var (
+	ErrMethodConflict = errors.New("method conflict")
+)
+
+// methodFuncs returns the methods of the interface, including the methods of the embedded interfaces.
+func (i *Interface) methodFuncs() []*types.Func {
+	iface, ok := types.Unalias(i.typ.typ).Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+
+	funcs := make([]*types.Func, 0, iface.NumMethods())
+	for j := range iface.NumMethods() {
+		funcs = append(funcs, iface.Method(j))
+	}
+
+	return funcs
+}
+
+func (i *Interface) String() string {
+	return types.TypeString(i.typ.typ, func(pkg *types.Package) string {
+		if pkg.Path() == i.typ.localPath {
+			return ""
+		}
+
+		return pkg.Name()
+	})
+}
+
+// checkMethodConflicts reports methods that have the same name but different signatures in the interfaces.
+// Such interfaces can not be implemented by a single type.
+func checkMethodConflicts(interfaces []*Interface) error {
+	type declaredMethod struct {
+		intrfc *Interface
+		fn     *types.Func
+	}
+
+	methodMap := map[string]declaredMethod{}
+	for _, intrfc := range interfaces {
+		for _, fn := range intrfc.methodFuncs() {
+			declared, ok := methodMap[fn.Name()]
+			if !ok {
+				methodMap[fn.Name()] = declaredMethod{
+					intrfc: intrfc,
+					fn:     fn,
+				}
+				continue
+			}
+
+			if !types.Identical(declared.fn.Type(), fn.Type()) {
+				return fmt.Errorf("%w: method %s of %s(%s) conflicts with method %s of %s(%s)",
+					ErrMethodConflict,
+					fn.Name(), intrfc, fn.Type(),
+					declared.fn.Name(), declared.intrfc, declared.fn.Type(),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// invalidMethod returns the first method of the interface whose signature contains a type which failed to type-check,
+// since such a type can not be written in the generated code. nil means every method is valid.
+func (i *Interface) invalidMethod() *types.Func {
+	for _, fn := range i.methodFuncs() {
+		if hasInvalidType(fn.Type()) {
+			return fn
+		}
+	}
+
+	return nil
+}
+
+// hasInvalidType reports whether the type contains the invalid type.
+// Named types are not followed into their underlying types, since they are written by name.
+func hasInvalidType(typ types.Type) bool {
+	hasInvalidTuple := func(tuple *types.Tuple) bool {
+		for j := range tuple.Len() {
+			if hasInvalidType(tuple.At(j).Type()) {
+				return true
+			}
+		}
+		return false
+	}
+	hasInvalidTypeList := func(list *types.TypeList) bool {
+		for j := range list.Len() {
+			if hasInvalidType(list.At(j)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch t := typ.(type) {
+	case nil:
+		return true
+	case *types.Basic:
+		return t.Kind() == types.Invalid
+	case *types.Pointer:
+		return hasInvalidType(t.Elem())
+	case *types.Slice:
+		return hasInvalidType(t.Elem())
+	case *types.Array:
+		return hasInvalidType(t.Elem())
+	case *types.Chan:
+		return hasInvalidType(t.Elem())
+	case *types.Map:
+		return hasInvalidType(t.Key()) || hasInvalidType(t.Elem())
+	case *types.Signature:
+		return hasInvalidTuple(t.Params()) || hasInvalidTuple(t.Results())
+	case *types.Struct:
+		for j := range t.NumFields() {
+			if hasInvalidType(t.Field(j).Type()) {
+				return true
+			}
+		}
+	case *types.Interface:
+		for j := range t.NumExplicitMethods() {
+			if hasInvalidType(t.ExplicitMethod(j).Type()) {
+				return true
+			}
+		}
+		for j := range t.NumEmbeddeds() {
+			if hasInvalidType(t.EmbeddedType(j)) {
+				return true
+			}
+		}
+	case *types.Union:
+		for j := range t.Len() {
+			if hasInvalidType(t.Term(j).Type()) {
+				return true
+			}
+		}
+	case *types.Named:
+		return hasInvalidTypeList(t.TypeArgs())
+	case *types.Alias:
+		return hasInvalidTypeList(t.TypeArgs())
+	}
+
+	return false
+}
+
+// caseEmbedder chooses the interfaces embedded in the struct types returned by the wrapper function.
+// When interfaces embedded in a struct share methods, the selectors of the methods are ambiguous
+// and the struct does not implement them.
+// So the methods already provided by the preceding interfaces are removed by embedding anonymous interfaces
+// declared with only the remaining methods.
+// Interfaces with the same name from different packages (e.g. math/rand.Source and math/rand/v2.Source)
+// would declare the same field, so they are embedded through anonymous interfaces as well.
+type caseEmbedder struct {
+	prefix     string
+	typeParams []*TypeParam
+	helperMap  map[string]*Interface
+	helpers    []*Interface
+}
+
+func newCaseEmbedder(prefix string, typeParams []*TypeParam, interfaces []*Interface) *caseEmbedder {
+	helperMap := map[string]*Interface{}
+	for _, intrfc := range interfaces {
+		// the anonymous interfaces of inline methods are already declared
+		if len(intrfc.methods) != 0 {
+			helperMap[intrfc.name] = intrfc
+		}
+	}
+
+	return &caseEmbedder{
+		prefix:     prefix,
+		typeParams: typeParams,
+		helperMap:  helperMap,
+	}
+}
+
+// embed returns the interfaces to embed for a struct implementing all of the interfaces,
+// with the indexes of the interfaces providing them.
+func (ce *caseEmbedder) embed(interfaces []*Interface) ([]*Interface, []int) {
+	provided := map[string]struct{}{}
+	fieldNames := map[string]struct{}{}
+	embedded := make([]*Interface, 0, len(interfaces))
+	sources := make([]int, 0, len(interfaces))
+	for i, intrfc := range interfaces {
+		funcs := intrfc.methodFuncs()
+
+		remaining := make([]*types.Func, 0, len(funcs))
+		for _, fn := range funcs {
+			if _, ok := provided[fn.Name()]; ok {
+				continue
+			}
+			provided[fn.Name()] = struct{}{}
+			remaining = append(remaining, fn)
+		}
+
+		_, fieldConflict := fieldNames[intrfc.name]
+
+		var embeddedInterface *Interface
+		switch {
+		case len(remaining) == 0:
+			// all methods are already provided
+			continue
+		case len(remaining) == len(funcs) && !fieldConflict:
+			embeddedInterface = intrfc
+		default:
+			embeddedInterface = ce.helper(intrfc.typ.localPath, remaining)
+		}
+
+		fieldNames[embeddedInterface.name] = struct{}{}
+		embedded = append(embedded, embeddedInterface)
+		sources = append(sources, i)
+	}
+
+	return embedded, sources
+}
+
+func (ce *caseEmbedder) helper(localPath string, funcs []*types.Func) *Interface {
+	var sb strings.Builder
+	sb.WriteString(ce.prefix)
+	for _, fn := range funcs {
+		sb.WriteString(fn.Name())
+	}
+	name := sb.String()
+
+	if helper, ok := ce.helperMap[name]; ok {
+		return helper
+	}
+
+	methods := make([]*Method, 0, len(funcs))
+	helperFuncs := make([]*types.Func, 0, len(funcs))
+	for _, fn := range funcs {
+		sig := fn.Type().(*types.Signature)
+		sig = types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic())
+
+		methods = append(methods, NewMethod(fn.Name(), &Type{typ: sig, localPath: localPath}))
+		helperFuncs = append(helperFuncs, types.NewFunc(fn.Pos(), fn.Pkg(), fn.Name(), sig))
+	}
+
+	typ := types.NewInterfaceType(helperFuncs, nil)
+	typ.Complete()
+
+	helper := NewInlineInterface(name, &Type{typ: typ, localPath: localPath}, ce.typeParams, methods)
+	ce.helperMap[name] = helper
+	ce.helpers = append(ce.helpers, helper)
+
+	return helper
+}
@@ -0,0 +1,142 @@
+package iwrapper
+
+import (
+	"go/ast"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// namer chooses identifiers of the generated code which do not collide with the names in scope.
+type namer struct {
+	taken map[string]struct{}
+}
+
+func newNamer() *namer {
+	return &namer{
+		taken: map[string]struct{}{},
+	}
+}
+
+// reserve marks the names as taken.
+func (n *namer) reserve(names ...string) {
+	for _, name := range names {
+		n.taken[name] = struct{}{}
+	}
+}
+
+// fresh returns the first name not taken among the base and the numbered names (e.g. v1, v2), and takes it.
+func (n *namer) fresh(base string) string {
+	name := base
+	for i := 1; n.isTaken(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	n.reserve(name)
+
+	return name
+}
+
+// freshGroup returns num names sharing a prefix (e.g. i0, i1), and takes them.
+// The prefix is the base, or the numbered base followed by an underscore (e.g. i1_0, i1_1) if any name is taken.
+func (n *namer) freshGroup(base string, num int) []string {
+	for i := 0; ; i++ {
+		prefix := base
+		if i != 0 {
+			prefix = base + strconv.Itoa(i) + "_"
+		}
+
+		names := make([]string, 0, num)
+		for j := range num {
+			names = append(names, prefix+strconv.Itoa(j))
+		}
+
+		if !slices.ContainsFunc(names, n.isTaken) {
+			n.reserve(names...)
+			return names
+		}
+	}
+}
+
+func (n *namer) isTaken(name string) bool {
+	_, ok := n.taken[name]
+	return ok
+}
+
+// identGroup is the identifiers named with a shared prefix from the base (e.g. i0, i1).
+type identGroup struct {
+	base   string
+	idents []*ast.Ident
+}
+
+// renameLocals renames the local identifiers of the function so that they do not shadow
+// the other identifiers referenced in it or the reserved names.
+// The identifiers in locals are renamed in order from their names, and then the identifiers in groups are named.
+func renameLocals(decl *ast.FuncDecl, reserved []string, locals []*ast.Ident, groups ...identGroup) {
+	localSet := map[*ast.Ident]struct{}{}
+	for _, ident := range locals {
+		localSet[ident] = struct{}{}
+	}
+	for _, group := range groups {
+		for _, ident := range group.idents {
+			localSet[ident] = struct{}{}
+		}
+	}
+
+	n := newNamer()
+	n.reserve(reserved...)
+	ast.Inspect(decl, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			// the selector is resolved in the package of the operand, not in the scope of the function
+			ast.Inspect(node.X, func(node ast.Node) bool {
+				if ident, ok := node.(*ast.Ident); ok {
+					if _, ok := localSet[ident]; !ok {
+						n.reserve(ident.Name)
+					}
+				}
+				return true
+			})
+			return false
+		case *ast.Ident:
+			if _, ok := localSet[node]; !ok {
+				n.reserve(node.Name)
+			}
+		}
+
+		return true
+	})
+
+	for _, ident := range locals {
+		ident.Name = n.fresh(ident.Name)
+	}
+
+	for _, group := range groups {
+		if len(group.idents) == 0 {
+			continue
+		}
+
+		for i, name := range n.freshGroup(group.base, len(group.idents)) {
+			group.idents[i].Name = name
+		}
+	}
+}
+
+// packageScopeNames returns the names declared at the package level, except in generated files.
+// The names declared in generated files are excluded, so that the previous output does not change the next one.
+func packageScopeNames(pkg *packages.Package) []string {
+	if pkg.Types == nil {
+		return nil
+	}
+
+	scope := pkg.Types.Scope()
+	names := make([]string, 0, scope.Len())
+	for _, name := range scope.Names() {
+		if isGeneratedObject(pkg, scope.Lookup(name)) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
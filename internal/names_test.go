@@ -0,0 +1,90 @@
+package iwrapper
+
+import "testing"
+
+func TestNamerFresh(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		reserved    []string
+		bases       []string
+		expected    []string
+	}{{
+		description: "衝突しなければそのままの名前になる",
+		bases:       []string{"v", "wrapper", "wrapped", "i", "ok"},
+		expected:    []string{"v", "wrapper", "wrapped", "i", "ok"},
+	}, {
+		description: "予約済みの名前には番号が付く",
+		reserved:    []string{"v", "ok", "ok1"},
+		bases:       []string{"v", "ok"},
+		expected:    []string{"v1", "ok2"},
+	}, {
+		description: "払い出した名前は再度使われない",
+		bases:       []string{"v", "v", "v"},
+		expected:    []string{"v", "v1", "v2"},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			n := newNamer()
+			n.reserve(testCase.reserved...)
+
+			names := make([]string, 0, len(testCase.bases))
+			for _, base := range testCase.bases {
+				names = append(names, n.fresh(base))
+			}
+
+			if diff := diff(testCase.expected, names); diff != "" {
+				t.Errorf("names: (-expected +actual)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNamerFreshGroup(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		reserved    []string
+		num         int
+		expected    []string
+	}{{
+		description: "衝突しなければbaseに番号が付く",
+		num:         3,
+		expected:    []string{"i0", "i1", "i2"},
+	}, {
+		description: "1つでも衝突すればprefixが変わる",
+		reserved:    []string{"i2"},
+		num:         3,
+		expected:    []string{"i1_0", "i1_1", "i1_2"},
+	}, {
+		description: "衝突しないprefixが見つかるまで探す",
+		reserved:    []string{"i0", "i1_1"},
+		num:         2,
+		expected:    []string{"i2_0", "i2_1"},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			n := newNamer()
+			n.reserve(testCase.reserved...)
+
+			names := n.freshGroup("i", testCase.num)
+			if diff := diff(testCase.expected, names); diff != "" {
+				t.Errorf("names: (-expected +actual)\n%s", diff)
+			}
+
+			for _, name := range names {
+				if !n.isTaken(name) {
+					t.Errorf("name %s is not taken", name)
+				}
+			}
+		})
+	}
+}
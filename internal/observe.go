@@ -0,0 +1,167 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const observeLoadMode = packages.NeedName |
+	packages.NeedTypes |
+	packages.NeedImports |
+	packages.NeedDeps
+
+var (
+	ErrObserveGeneric = errors.New("observation of generic target is not supported")
+)
+
+// methodKey identifies a method across separately loaded packages, where the identities of the types differ.
+type methodKey struct {
+	id, signature string
+}
+
+func newMethodKey(fn *types.Func) methodKey {
+	sig := fn.Signature()
+
+	// parameter names are not a part of the identity of signatures
+	var sb strings.Builder
+	sb.WriteString("func(")
+	writeTupleTypes(&sb, sig.Params(), sig.Variadic())
+	sb.WriteString(")(")
+	writeTupleTypes(&sb, sig.Results(), false)
+	sb.WriteString(")")
+
+	return methodKey{
+		id:        fn.Id(),
+		signature: sb.String(),
+	}
+}
+
+func writeTupleTypes(sb *strings.Builder, tuple *types.Tuple, variadic bool) {
+	for i := range tuple.Len() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		typ := tuple.At(i).Type()
+		if variadic && i == tuple.Len()-1 {
+			sb.WriteString("...")
+			typ = typ.(*types.Slice).Elem()
+		}
+		sb.WriteString(types.TypeString(typ, nil))
+	}
+}
+
+// observeCombinations returns the combinations of the optional interfaces implemented by the concrete types
+// that are declared in the packages matched by patterns and their dependencies, and implement all required interfaces.
+// The patterns are resolved relative to dir.
+func observeCombinations(dir string, patterns []string, requiredInterfaces, optionalInterfaces []*Interface) ([]uint64, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: observeLoadMode,
+		Dir:  dir,
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoPackages, strings.Join(patterns, ","))
+	}
+
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			// type errors are tolerated because stale generated files may not compile.
+			if pkgErr.Kind == packages.TypeError {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to load package(%s): %w", pkg.PkgPath, pkgErr)
+		}
+	}
+
+	var requiredKeys []methodKey
+	for _, intrfc := range requiredInterfaces {
+		requiredKeys = append(requiredKeys, intrfc.methodKeys()...)
+	}
+
+	optionalKeys := make([][]methodKey, 0, len(optionalInterfaces))
+	for _, intrfc := range optionalInterfaces {
+		optionalKeys = append(optionalKeys, intrfc.methodKeys())
+	}
+
+	observed := map[uint64]struct{}{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil {
+			return
+		}
+
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+
+			named, ok := obj.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() != 0 || types.IsInterface(named) {
+				continue
+			}
+
+			// values and pointers have different method sets
+			for _, typ := range []types.Type{named, types.NewPointer(named)} {
+				methodSet := map[methodKey]struct{}{}
+				selections := types.NewMethodSet(typ)
+				for i := range selections.Len() {
+					if fn, ok := selections.At(i).Obj().(*types.Func); ok {
+						methodSet[newMethodKey(fn)] = struct{}{}
+					}
+				}
+
+				if !containsAllKeys(methodSet, requiredKeys) {
+					continue
+				}
+
+				var mask uint64
+				for i, keys := range optionalKeys {
+					if containsAllKeys(methodSet, keys) {
+						mask |= 1 << i
+					}
+				}
+				observed[mask] = struct{}{}
+			}
+		}
+	})
+
+	masks := make([]uint64, 0, len(observed))
+	for mask := range observed {
+		masks = append(masks, mask)
+	}
+	slices.Sort(masks)
+
+	return masks, nil
+}
+
+// methodKeys returns the keys of the methods of the interface.
+func (i *Interface) methodKeys() []methodKey {
+	funcs := i.methodFuncs()
+	keys := make([]methodKey, 0, len(funcs))
+	for _, fn := range funcs {
+		keys = append(keys, newMethodKey(fn))
+	}
+
+	return keys
+}
+
+func containsAllKeys(methodSet map[methodKey]struct{}, keys []methodKey) bool {
+	for _, key := range keys {
+		if _, ok := methodSet[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,163 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	ErrGeneratedCode = errors.New("generated code does not compile")
+)
+
+// Output is the generated source of a file.
+type Output struct {
+	Src []byte
+	// owners are the targets generating each declaration of Src except the import declaration
+	owners []*GenerateConfig
+}
+
+// Check type-checks the source as the file at path, together with the other files of the package in its directory.
+// Type errors are reported at the declarations of the targets generating the code causing them.
+func (o *Output) Check(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:    loadMode,
+		Dir:     filepath.Dir(absPath),
+		Overlay: map[string][]byte{absPath: o.Src},
+	}, "file="+absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load package: %w", err)
+	}
+
+	var errs []error
+	for _, pkg := range pkgs {
+		for _, typeErrs := range typeErrorGroups(pkg.TypeErrors) {
+			// the error is caused by the generated code if the error or its continuation is located in it,
+			// e.g. the redeclaration of a name declared in another file is reported in that file
+			var (
+				msgs    []string
+				diagPos token.Position
+				found   bool
+			)
+			for _, typeErr := range typeErrs {
+				pos := typeErr.Fset.Position(typeErr.Pos)
+				msgs = append(msgs, fmt.Sprintf("%s: %s", pos, strings.TrimPrefix(typeErr.Msg, "\t")))
+				if pos.Filename != absPath || found {
+					continue
+				}
+
+				found = true
+				diagPos = pos
+				if owner := o.owner(pos.Offset); owner != nil {
+					diagPos = owner.Pos
+				}
+			}
+			if !found {
+				continue
+			}
+
+			err := fmt.Errorf("%w: %s", ErrGeneratedCode, strings.Join(msgs, ", "))
+			errs = append(errs, NewDiagnostic(diagPos, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// typeErrorGroups groups each type error with its continuation errors following it,
+// whose messages start with a tab (e.g. "\tother declaration of X").
+func typeErrorGroups(typeErrs []types.Error) [][]types.Error {
+	var groups [][]types.Error
+	for _, typeErr := range typeErrs {
+		if strings.HasPrefix(typeErr.Msg, "\t") && len(groups) != 0 {
+			groups[len(groups)-1] = append(groups[len(groups)-1], typeErr)
+			continue
+		}
+
+		groups = append(groups, []types.Error{typeErr})
+	}
+
+	return groups
+}
+
+// owner returns the target generating the declaration at the offset in the source.
+func (o *Output) owner(offset int) *GenerateConfig {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", o.Src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	var decls []ast.Decl
+	for _, decl := range f.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+
+		decls = append(decls, decl)
+	}
+
+	if len(decls) != len(o.owners) {
+		return nil
+	}
+
+	for i, decl := range decls {
+		if fset.Position(decl.Pos()).Offset <= offset && offset < fset.Position(decl.End()).Offset {
+			return o.owners[i]
+		}
+	}
+
+	return nil
+}
+
+// WriteFile writes the source to path atomically, so that a failure never leaves a broken file.
+// The permission of the existing file is kept.
+func WriteFile(path string, src []byte) (err error) {
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(src); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to change permission of temporary file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
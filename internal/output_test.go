@@ -0,0 +1,85 @@
+package iwrapper
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputCheck(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join("testdata", "multi_target.go")
+	pkgName, results, err := ParseTarget(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confs, err := Convert(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the function of the second target conflicts with the declaration of the package
+	confs[1].FuncName = "Normal"
+
+	output, err := Render(pkgName, confs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = output.Check(filepath.Join("testdata", "iwrapper_test_generated.go"))
+	if !errors.Is(err, ErrGeneratedCode) {
+		t.Fatalf("error: expected %v, got %v", ErrGeneratedCode, err)
+	}
+
+	// the redeclaration is reported in the file of the package, with its continuation in the generated file
+	if !strings.Contains(err.Error(), "Normal redeclared") {
+		t.Errorf("error: expected the redeclaration, got %v", err)
+	}
+
+	for _, diagnostic := range Diagnostics(err) {
+		if diagnostic.Pos != confs[1].Pos {
+			t.Errorf("position: expected %s, got %s", confs[1].Pos, diagnostic.Pos)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "iwrapper_gen.go")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("content: expected %q, got %q", "new", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permission: expected %o, got %o", 0o600, perm)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("entries: expected %d, got %d", 1, len(entries))
+	}
+}
@@ -0,0 +1,296 @@
+package iwrapper
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+const (
+	// MaxOptionalInterfaces is the maximum number of optional interfaces of a target,
+	// which is the width of the mask of the optional interfaces.
+	MaxOptionalInterfaces = 64
+	// DefaultMaxCases is the default maximum number of the cases of the switch generated for a target.
+	// Targets with up to 8 optional interfaces are generated exhaustively.
+	DefaultMaxCases = 1 << 8
+)
+
+var (
+	ErrTooManyOptionalInterfaces = fmt.Errorf("too many optional interfaces (max %d)", MaxOptionalInterfaces)
+	ErrInvalidMaxCases           = errors.New("invalid max cases")
+	ErrInvalidFallback           = errors.New("invalid fallback")
+)
+
+// Fallback is how values implementing a combination without a case are wrapped.
+type Fallback int
+
+const (
+	// FallbackSubset wraps the values with the case of the largest generated combination included in their combination.
+	FallbackSubset Fallback = iota
+	// FallbackRequired wraps the values with the case without optional interfaces.
+	FallbackRequired
+)
+
+// ParseFallback parses the name of the fallback.
+func ParseFallback(name string) (Fallback, error) {
+	switch name {
+	case "subset":
+		return FallbackSubset, nil
+	case "required":
+		return FallbackRequired, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrInvalidFallback, name)
+	}
+}
+
+func (f Fallback) String() string {
+	switch f {
+	case FallbackSubset:
+		return "subset"
+	case FallbackRequired:
+		return "required"
+	default:
+		return fmt.Sprintf("Fallback(%d)", int(f))
+	}
+}
+
+// CasePlan is the set of combinations of the optional interfaces the wrapper function generates cases for.
+//
+// Only the combinations allowed by the constraints of the target are generated.
+// If all of them fit in the max cases, every allowed combination is generated.
+// Otherwise, allowed combinations are taken in ascending order of the number of optional interfaces,
+// and the combination of all optional interfaces is included if it is allowed (bounded plan).
+// Values implementing a combination without a case get the case of the largest generated combination included in it.
+//
+// A plan created by NewObservedCasePlan only generates the combinations observed on the concrete types,
+// and values implementing the other combinations are wrapped according to the fallback.
+type CasePlan struct {
+	masks []uint64
+	// exhaustive is true if every combination of the optional interfaces has its case
+	exhaustive bool
+	fallback   Fallback
+}
+
+func NewCasePlan(optionalInterfaces []*Interface, constraints []*Constraint, maxCases int) (*CasePlan, error) {
+	optionalNum := len(optionalInterfaces)
+	if optionalNum > MaxOptionalInterfaces {
+		return nil, fmt.Errorf("%w: %d optional interfaces", ErrTooManyOptionalInterfaces, optionalNum)
+	}
+
+	if maxCases <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMaxCases, maxCases)
+	}
+
+	maskConstraints, err := newMaskConstraints(optionalInterfaces, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(maskConstraints) == 0 && optionalNum < bits.Len(uint(maxCases)) {
+		masks := make([]uint64, 0, 1<<optionalNum)
+		for mask := uint64(0); mask < 1<<optionalNum; mask++ {
+			masks = append(masks, mask)
+		}
+
+		return &CasePlan{
+			masks:      masks,
+			exhaustive: true,
+		}, nil
+	}
+
+	allows := func(mask uint64) bool {
+		return allowsAll(maskConstraints, mask)
+	}
+
+	masks, complete := enumerateAllowed(optionalNum, maskConstraints, maxCases)
+	if !complete {
+		var fullMask uint64 = 1<<optionalNum - 1
+		if optionalNum == MaxOptionalInterfaces {
+			fullMask = ^uint64(0)
+		}
+
+		// the combination without optional interfaces comes first as the fallback of all combinations
+		limit := maxCases
+		includesFull := maxCases >= 2 && allows(fullMask)
+		if includesFull {
+			limit--
+		}
+
+		masks = make([]uint64, 0, maxCases)
+		for size := 0; size < optionalNum && len(masks) < limit; size++ {
+			masks = appendCombinations(masks, optionalNum, size, limit, allows)
+		}
+		if includesFull {
+			masks = append(masks, fullMask)
+		}
+	}
+
+	// larger combinations first, so that the first matching case is the largest one
+	slices.SortStableFunc(masks, func(x, y uint64) int {
+		if c := bits.OnesCount64(y) - bits.OnesCount64(x); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(x, y)
+	})
+
+	return &CasePlan{
+		masks:      masks,
+		exhaustive: false,
+	}, nil
+}
+
+// NewObservedCasePlan creates the plan generating cases only for the observed combinations allowed by the constraints
+// and the combination without optional interfaces.
+// If they exceed the max cases, combinations with fewer optional interfaces are taken first.
+func NewObservedCasePlan(optionalInterfaces []*Interface, constraints []*Constraint, observed []uint64, maxCases int, fallback Fallback) (*CasePlan, error) {
+	optionalNum := len(optionalInterfaces)
+	if optionalNum > MaxOptionalInterfaces {
+		return nil, fmt.Errorf("%w: %d optional interfaces", ErrTooManyOptionalInterfaces, optionalNum)
+	}
+
+	if maxCases <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMaxCases, maxCases)
+	}
+
+	maskConstraints, err := newMaskConstraints(optionalInterfaces, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	masks := []uint64{0}
+	for _, mask := range observed {
+		if mask != 0 && allowsAll(maskConstraints, mask) {
+			masks = append(masks, mask)
+		}
+	}
+
+	// fewer combinations first, so that the combination without optional interfaces is always kept
+	slices.SortFunc(masks, func(x, y uint64) int {
+		if c := bits.OnesCount64(x) - bits.OnesCount64(y); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(x, y)
+	})
+	masks = slices.Compact(masks)
+	if len(masks) > maxCases {
+		masks = masks[:maxCases]
+	}
+
+	exhaustive := optionalNum < MaxOptionalInterfaces && len(masks) == 1<<optionalNum
+	switch {
+	case exhaustive:
+		slices.Sort(masks)
+	case fallback == FallbackRequired:
+		// the combination without optional interfaces is the default case, which comes last
+		slices.Sort(masks)
+		masks = append(masks[1:], 0)
+	default:
+		// larger combinations first, so that the first matching case is the largest one
+		slices.SortStableFunc(masks, func(x, y uint64) int {
+			if c := bits.OnesCount64(y) - bits.OnesCount64(x); c != 0 {
+				return c
+			}
+
+			return cmp.Compare(x, y)
+		})
+	}
+
+	return &CasePlan{
+		masks:      masks,
+		exhaustive: exhaustive,
+		fallback:   fallback,
+	}, nil
+}
+
+// enumerateAllowed returns all combinations allowed by the constraints.
+// If the number of the combinations exceeds limit, it gives up and returns false.
+func enumerateAllowed(n int, constraints []*maskConstraint, limit int) ([]uint64, bool) {
+	// constraints are checked when all interfaces they depend on are decided
+	checkAt := make([][]*maskConstraint, n)
+	for _, constraint := range constraints {
+		top := bits.Len64(constraint.bits()) - 1
+		checkAt[top] = append(checkAt[top], constraint)
+	}
+
+	var masks []uint64
+	var walk func(index int, mask uint64) bool
+	walk = func(index int, mask uint64) bool {
+		if index == n {
+			if len(masks) >= limit {
+				return false
+			}
+			masks = append(masks, mask)
+
+			return true
+		}
+
+		for _, candidate := range []uint64{mask, mask | 1<<index} {
+			if !allowsAll(checkAt[index], candidate) {
+				continue
+			}
+
+			if !walk(index+1, candidate) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if !walk(0, 0) {
+		return nil, false
+	}
+
+	return masks, true
+}
+
+// appendCombinations appends the allowed masks with size bits set out of n bits in lexicographical order,
+// until the number of masks reaches limit.
+func appendCombinations(masks []uint64, n, size, limit int, allows func(uint64) bool) []uint64 {
+	indices := make([]int, size)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	for len(masks) < limit {
+		var mask uint64
+		for _, index := range indices {
+			mask |= 1 << index
+		}
+		if allows(mask) {
+			masks = append(masks, mask)
+		}
+
+		// advance to the next combination
+		i := size - 1
+		for i >= 0 && indices[i] == n-size+i {
+			i--
+		}
+		if i < 0 {
+			break
+		}
+
+		indices[i]++
+		for j := i + 1; j < size; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+	}
+
+	return masks
+}
+
+func (cp *CasePlan) Masks() []uint64 {
+	return cp.masks
+}
+
+func (cp *CasePlan) Exhaustive() bool {
+	return cp.exhaustive
+}
+
+func (cp *CasePlan) Fallback() Fallback {
+	return cp.fallback
+}
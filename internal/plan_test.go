@@ -0,0 +1,234 @@
+package iwrapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewCasePlan(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description        string
+		optionalNum        int
+		maxCases           int
+		expectedMasks      []uint64
+		expectedExhaustive bool
+		expectedErr        error
+	}{{
+		description:        "max casesに収まる場合は全組み合わせを生成する",
+		optionalNum:        2,
+		maxCases:           4,
+		expectedMasks:      []uint64{0b00, 0b01, 0b10, 0b11},
+		expectedExhaustive: true,
+	}, {
+		description:        "optionalがない場合は1caseのみ",
+		optionalNum:        0,
+		maxCases:           DefaultMaxCases,
+		expectedMasks:      []uint64{0},
+		expectedExhaustive: true,
+	}, {
+		description:        "max casesを超える場合は少ない組み合わせから生成し、全interfaceの組み合わせを含める",
+		optionalNum:        3,
+		maxCases:           5,
+		expectedMasks:      []uint64{0b111, 0b001, 0b010, 0b100, 0b000},
+		expectedExhaustive: false,
+	}, {
+		description:        "max casesを超える場合は大きい組み合わせから並べる",
+		optionalNum:        3,
+		maxCases:           7,
+		expectedMasks:      []uint64{0b111, 0b011, 0b101, 0b001, 0b010, 0b100, 0b000},
+		expectedExhaustive: false,
+	}, {
+		description:        "optionalが上限ちょうどでも生成できる",
+		optionalNum:        MaxOptionalInterfaces,
+		maxCases:           2,
+		expectedMasks:      []uint64{^uint64(0), 0},
+		expectedExhaustive: false,
+	}, {
+		description:        "max casesが1の場合はoptionalなしのcaseのみ",
+		optionalNum:        3,
+		maxCases:           1,
+		expectedMasks:      []uint64{0},
+		expectedExhaustive: false,
+	}, {
+		description: "optionalが上限を超える場合はエラー",
+		optionalNum: MaxOptionalInterfaces + 1,
+		maxCases:    DefaultMaxCases,
+		expectedErr: ErrTooManyOptionalInterfaces,
+	}, {
+		description: "max casesが0以下の場合はエラー",
+		optionalNum: 1,
+		maxCases:    0,
+		expectedErr: ErrInvalidMaxCases,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			optionalInterfaces := make([]*Interface, 0, testCase.optionalNum)
+			for range testCase.optionalNum {
+				optionalInterfaces = append(optionalInterfaces, &Interface{})
+			}
+
+			plan, err := NewCasePlan(optionalInterfaces, nil, testCase.maxCases)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("error: expected %v, got %v", testCase.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if plan.Exhaustive() != testCase.expectedExhaustive {
+				t.Errorf("exhaustive: expected %t, got %t", testCase.expectedExhaustive, plan.Exhaustive())
+			}
+
+			if diff := diff(plan.Masks(), testCase.expectedMasks); diff != "" {
+				t.Errorf("masks diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewCasePlanWithConstraints(t *testing.T) {
+	t.Parallel()
+
+	hijacker, closeNotifier, readerFrom, stringWriter, pusher := &Interface{}, &Interface{}, &Interface{}, &Interface{}, &Interface{}
+	optionalInterfaces := []*Interface{hijacker, closeNotifier, readerFrom, stringWriter, pusher}
+
+	constraints := []*Constraint{{
+		Kind:       ConstraintGroup,
+		Interfaces: []*Interface{hijacker, closeNotifier},
+	}, {
+		Kind:       ConstraintImplies,
+		Interfaces: []*Interface{readerFrom, stringWriter},
+	}, {
+		Kind:       ConstraintExcludes,
+		Interfaces: []*Interface{pusher, hijacker},
+	}}
+
+	testCases := []struct {
+		description   string
+		maxCases      int
+		expectedMasks []uint64
+	}{{
+		description: "constraintを満たす組み合わせのみ生成する",
+		maxCases:    DefaultMaxCases,
+		expectedMasks: []uint64{
+			0b01111,
+			0b01011,
+			0b11100,
+			0b00011,
+			0b01100,
+			0b11000,
+			0b01000,
+			0b10000,
+			0b00000,
+		},
+	}, {
+		description: "max casesを超える場合は少ない組み合わせから生成する",
+		maxCases:    4,
+		expectedMasks: []uint64{
+			0b00011,
+			0b01000,
+			0b10000,
+			0b00000,
+		},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := NewCasePlan(optionalInterfaces, constraints, testCase.maxCases)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if plan.Exhaustive() {
+				t.Error("exhaustive: expected false, got true")
+			}
+
+			if diff := diff(plan.Masks(), testCase.expectedMasks); diff != "" {
+				t.Errorf("masks diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNewObservedCasePlan(t *testing.T) {
+	t.Parallel()
+
+	flusher, closer, pusher := &Interface{}, &Interface{}, &Interface{}
+	optionalInterfaces := []*Interface{flusher, closer, pusher}
+
+	testCases := []struct {
+		description        string
+		observed           []uint64
+		constraints        []*Constraint
+		maxCases           int
+		fallback           Fallback
+		expectedMasks      []uint64
+		expectedExhaustive bool
+	}{{
+		description:   "観測された組み合わせとoptionalなしの組み合わせのみ生成する",
+		observed:      []uint64{0b001, 0b011, 0b110},
+		maxCases:      DefaultMaxCases,
+		expectedMasks: []uint64{0b011, 0b110, 0b001, 0b000},
+	}, {
+		description:   "fallbackがrequiredの場合は昇順に並べ、optionalなしの組み合わせを最後にする",
+		observed:      []uint64{0b110, 0b011, 0b001},
+		maxCases:      DefaultMaxCases,
+		fallback:      FallbackRequired,
+		expectedMasks: []uint64{0b001, 0b011, 0b110, 0b000},
+	}, {
+		description:   "観測されなかった場合はoptionalなしのcaseのみ",
+		observed:      []uint64{},
+		maxCases:      DefaultMaxCases,
+		expectedMasks: []uint64{0b000},
+	}, {
+		description:        "全組み合わせが観測された場合は網羅的",
+		observed:           []uint64{0b000, 0b001, 0b010, 0b011, 0b100, 0b101, 0b110, 0b111},
+		maxCases:           DefaultMaxCases,
+		expectedMasks:      []uint64{0b000, 0b001, 0b010, 0b011, 0b100, 0b101, 0b110, 0b111},
+		expectedExhaustive: true,
+	}, {
+		description: "constraintを満たさない組み合わせは除く",
+		observed:    []uint64{0b001, 0b011, 0b110},
+		constraints: []*Constraint{{
+			Kind:       ConstraintExcludes,
+			Interfaces: []*Interface{pusher, closer},
+		}},
+		maxCases:      DefaultMaxCases,
+		expectedMasks: []uint64{0b011, 0b001, 0b000},
+	}, {
+		description:   "max casesを超える場合は少ない組み合わせから生成する",
+		observed:      []uint64{0b111, 0b011, 0b001},
+		maxCases:      3,
+		expectedMasks: []uint64{0b011, 0b001, 0b000},
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			plan, err := NewObservedCasePlan(optionalInterfaces, testCase.constraints, testCase.observed, testCase.maxCases, testCase.fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if plan.Exhaustive() != testCase.expectedExhaustive {
+				t.Errorf("exhaustive: expected %t, got %t", testCase.expectedExhaustive, plan.Exhaustive())
+			}
+
+			if plan.Fallback() != testCase.fallback {
+				t.Errorf("fallback: expected %s, got %s", testCase.fallback, plan.Fallback())
+			}
+
+			if diff := diff(plan.Masks(), testCase.expectedMasks); diff != "" {
+				t.Errorf("masks diff: %s", diff)
+			}
+		})
+	}
+}
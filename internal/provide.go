@@ -0,0 +1,54 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+)
+
+var (
+	ErrInvalidProvide = errors.New("invalid provide")
+)
+
+// isProvided reports whether the element has the provide directive.
+//
+//	//iwrapper:provide
+func isProvided(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, comment := range doc.List {
+		if _, ok := directiveArgs(comment.Text, provideDirectivePrefix); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkProvideDirectives reports the provide directives which can not be applied to the elements.
+// The provided interfaces are always embedded from the wrapped value, so they can not be required.
+func checkProvideDirectives(elements []*element) error {
+	for _, elem := range elements {
+		if elem.doc == nil {
+			continue
+		}
+
+		for _, comment := range elem.doc.List {
+			args, ok := directiveArgs(comment.Text, provideDirectivePrefix)
+			if !ok {
+				continue
+			}
+
+			if len(args) != 0 {
+				return fmt.Errorf("%w: %s takes no arguments", ErrInvalidProvide, provideDirectivePrefix)
+			}
+			if isRequired(elem.doc) {
+				return fmt.Errorf("%w: required interface(%s) can not be provided", ErrInvalidProvide, elem.intrfc)
+			}
+		}
+	}
+
+	return nil
+}
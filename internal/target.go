@@ -5,48 +5,176 @@ import (
 	"fmt"
 	"go/ast"
 	"go/format"
-	"go/parser"
 	"go/token"
-	"io"
-	"log"
+	"go/types"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/packages"
 )
 
 const (
-	toolPrefix             = "//iwrapper:"
-	targetDirectivePrefix  = toolPrefix + "target"
-	requireDirectivePrefix = toolPrefix + "require"
+	toolPrefix               = "//iwrapper:"
+	targetDirectivePrefix    = toolPrefix + "target"
+	requireDirectivePrefix   = toolPrefix + "require"
+	groupDirectivePrefix     = toolPrefix + "group"
+	impliesDirectivePrefix   = toolPrefix + "implies"
+	excludesDirectivePrefix  = toolPrefix + "excludes"
+	intersectDirectivePrefix = toolPrefix + "intersect"
+	provideDirectivePrefix   = toolPrefix + "provide"
+	hideDirectivePrefix      = toolPrefix + "hide"
 )
 
 type ParseResult struct {
 	FuncName, StructName                   string
+	TypeParams                             []*TypeParam
 	RequiredInterfaces, OptionalInterfaces []*Interface
+	// Constraints restrict the combinations of the optional interfaces.
+	Constraints []*Constraint
+	// MaxCases is the maximum number of the cases generated for the target. 0 means DefaultMaxCases.
+	MaxCases int
+	// Observed is the combinations of the optional interfaces implemented by the concrete types scanned for the target.
+	// nil means the target is not observed and every combination can occur.
+	Observed []uint64
+	// Fallback is how values implementing a combination which is not observed are wrapped.
+	Fallback Fallback
+	// Declare is true if the target is declared by a wrap directive, and the generated file declares its interface.
+	Declare bool
+	// Pos is the position of the declaration of the target.
+	Pos token.Position
+	// Scope is the names declared at the package level of the target, except in generated files.
+	Scope []string
+	// Unwrap is true if the wrappers of the target have the Unwrap method returning the original value.
+	Unwrap bool
+	// LookupDepth is the maximum number of Unwrap calls to look up the optional interfaces. 0 means no lookup.
+	LookupDepth int
+	// Capabilities is true if the variant of the wrapper function passing the capabilities to the wrapper is generated.
+	Capabilities bool
+	// Filter is true if the variant of the wrapper function filtering the capabilities at runtime is generated.
+	Filter bool
+	// Value is true if the variant of the wrapper function taking the wrapper value instead of the wrapper function is generated.
+	Value bool
+	// Mode is how the wrappers provide the optional interfaces.
+	Mode Mode
+	// ProvidedInterfaces are the interfaces the wrapped value always implements, excluded from the optional interfaces.
+	ProvidedInterfaces []*Interface
+	// Hidden is the optional interfaces the wrappers never provide, although the capabilities report them.
+	Hidden []*Interface
+	// Intersected is the optional interfaces provided only if both the original value and the wrapped value implement them.
+	Intersected []*Interface
+	// Base is true if the struct implementing the target by forwarding every method to the original value is generated.
+	Base bool
 }
 
 var (
 	ErrNoPkgName = errors.New("no package name")
+	ErrNoFile    = errors.New("file not found in loaded package")
 )
 
-func ParseTarget(r io.Reader) (string, []*ParseResult, error) {
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, "", r, parser.ParseComments)
+// ParseTarget collects the targets declared in the file at path.
+// The package containing the file is type-checked so that declarations in the other files can be referenced.
+func ParseTarget(path string) (string, []*ParseResult, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
+		return "", nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: loadMode,
+		Dir:  filepath.Dir(absPath),
+	}, "file="+absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load package: %w", err)
+	}
+
+	if err := checkPackageErrors(pkgs); err != nil {
 		return "", nil, err
 	}
 
-	if f.Name == nil {
-		return "", nil, ErrNoPkgName
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			if pkg.Fset.File(f.Pos()).Name() != absPath {
+				continue
+			}
+
+			if pkg.Name == "" {
+				return "", nil, ErrNoPkgName
+			}
+
+			results, err := parseFile(pkg, f)
+			if err != nil {
+				return "", nil, err
+			}
+
+			return pkg.Name, results, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrNoFile, path)
+}
+
+// ParsePackage collects the targets declared in every file of a package loaded with LoadPackages.
+func ParsePackage(pkg *packages.Package) ([]*ParseResult, error) {
+	var (
+		results []*ParseResult
+		errs    []error
+	)
+	for _, f := range pkg.Syntax {
+		fileResults, err := parseFile(pkg, f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		results = append(results, fileResults...)
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return results, nil
+}
+
+func parseFile(pkg *packages.Package, f *ast.File) ([]*ParseResult, error) {
+	if pkg.Types == nil || pkg.TypesInfo == nil {
+		return nil, fmt.Errorf("package(%s) has no type information", pkg.PkgPath)
+	}
+
+	var (
+		results []*ParseResult
+		errs    []error
+	)
+
+	directives, err := wrapDirectives(pkg.Fset, f)
+	if err != nil {
+		errs = append(errs, err)
 	}
 
-	pkgName := f.Name.Name
+	for _, directive := range directives {
+		result, err := parseWrapDirective(pkg, directive)
+		if err != nil {
+			errs = append(errs, withPosition(directive.pos, err))
+			continue
+		}
 
-	pkgMap := createImportMap(f.Imports)
+		results = append(results, result)
+	}
+
+	parseTarget := func(typeSpec *ast.TypeSpec, tag reflect.StructTag) {
+		result, err := parseTypeSpec(pkg, typeSpec, tag)
+		if err != nil {
+			errs = append(errs, withPosition(pkg.Fset.Position(typeSpec.Name.Pos()), err))
+			return
+		}
+
+		results = append(results, result)
+	}
 
-	var results []*ParseResult
 	for _, decl := range f.Decls {
 		genDecl, ok := decl.(*ast.GenDecl)
 		if !ok || genDecl == nil || genDecl.Tok != token.TYPE {
@@ -58,7 +186,6 @@ func ParseTarget(r io.Reader) (string, []*ParseResult, error) {
 			if !ok || typeSpec == nil || typeSpec.Name == nil {
 				continue
 			}
-			typeName := typeSpec.Name.Name
 
 			var docs []*ast.Comment
 			if genDecl.Doc != nil {
@@ -68,191 +195,547 @@ func ParseTarget(r io.Reader) (string, []*ParseResult, error) {
 				docs = append(docs, typeSpec.Doc.List...)
 			}
 
-			funcName, targeted := checkIsTargeted(docs)
+			tag, targeted := checkIsTargeted(docs)
 			if !targeted {
 				continue
 			}
 
-			interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
-			if !ok || interfaceType == nil || interfaceType.Methods == nil {
-				return "", nil, fmt.Errorf("non-interface type(%s) is targeted", typeName)
-			}
-
-			requireInterfaces, optionalInterfaces, err := createInterfaces(fset, pkgMap, interfaceType.Methods.List)
-			if err != nil {
-				return "", nil, fmt.Errorf("failed to create interfaces: %w", err)
-			}
-
-			results = append(results, &ParseResult{
-				FuncName:           funcName,
-				StructName:         typeName,
-				RequiredInterfaces: requireInterfaces,
-				OptionalInterfaces: optionalInterfaces,
-			})
+			parseTarget(typeSpec, tag)
 		} else {
 			for _, spec := range genDecl.Specs {
 				typeSpec, ok := spec.(*ast.TypeSpec)
 				if !ok || typeSpec == nil || typeSpec.Name == nil || typeSpec.Doc == nil {
 					continue
 				}
-				typeName := typeSpec.Name.Name
 
-				funcName, targeted := checkIsTargeted(typeSpec.Doc.List)
+				tag, targeted := checkIsTargeted(typeSpec.Doc.List)
 				if !targeted {
 					continue
 				}
 
-				interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
-				if !ok || interfaceType == nil || interfaceType.Methods == nil {
-					return "", nil, fmt.Errorf("non-interface type(%s) is targeted", typeName)
-				}
+				parseTarget(typeSpec, tag)
+			}
+		}
+	}
 
-				requireInterfaces, optionalInterfaces, err := createInterfaces(fset, pkgMap, interfaceType.Methods.List)
-				if err != nil {
-					return "", nil, fmt.Errorf("failed to create interfaces: %w", err)
-				}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return results, nil
+}
+
+func parseTypeSpec(pkg *packages.Package, typeSpec *ast.TypeSpec, tag reflect.StructTag) (*ParseResult, error) {
+	typeName := typeSpec.Name.Name
+	pos := pkg.Fset.Position(typeSpec.Name.Pos())
 
-				results = append(results, &ParseResult{
-					FuncName:           funcName,
-					StructName:         typeName,
-					RequiredInterfaces: requireInterfaces,
-					OptionalInterfaces: optionalInterfaces,
-				})
+	interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
+	if !ok || interfaceType == nil || interfaceType.Methods == nil {
+		return nil, fmt.Errorf("non-interface type(%s) is targeted", typeName)
+	}
+
+	var typeParams []*TypeParam
+	if obj, ok := pkg.TypesInfo.Defs[typeSpec.Name].(*types.TypeName); ok {
+		if named, ok := obj.Type().(*types.Named); ok {
+			tparams := named.TypeParams()
+			for i := range tparams.Len() {
+				tparam := tparams.At(i)
+				typeParams = append(typeParams, NewTypeParam(tparam.Obj().Name(), NewType(tparam.Constraint(), pkg.Types)))
 			}
 		}
 	}
 
-	return pkgName, results, nil
+	// the errors of the elements and the options are reported together, so that a run shows all problems of the target
+	var errs []error
+
+	var (
+		requireInterfaces, optionalInterfaces, providedInterfaces []*Interface
+		constraints                                               []*Constraint
+		intersected, hidden                                       []*Interface
+	)
+	elements, err := createInterfaces(pkg, &inlineNamer{
+		prefix:     lowerFirst(typeName),
+		typeParams: typeParams,
+	}, interfaceType.Methods.List)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to create interfaces: %w", err))
+	} else {
+		if err := checkProvideDirectives(elements); err != nil {
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid target(%s): %w", typeName, err)))
+		}
+
+		requireInterfaces, optionalInterfaces, providedInterfaces = splitElements(elements)
+
+		constraints, err = createConstraints(elements)
+		if err != nil {
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid constraints of target(%s): %w", typeName, err)))
+		}
+
+		intersected, err = intersectedInterfaces(elements)
+		if err != nil {
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid target(%s): %w", typeName, err)))
+		}
+
+		hidden, err = hiddenInterfaces(elements)
+		if err != nil {
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid target(%s): %w", typeName, err)))
+		}
+	}
+
+	result, err := newParseResult(pkg, pos, typeName, tag, typeParams, requireInterfaces, optionalInterfaces, providedInterfaces, constraints)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// the value returned by the wrapper function implements all optional interfaces in the implement mode
+	if len(intersected) != 0 && result != nil && result.Mode == ModeImplement {
+		errs = append(errs, NewDiagnostic(pos, fmt.Errorf("%s of target(%s) in mode(%s), expected mode(%s): %w", intersectDirectivePrefix, typeName, result.Mode, ModeDelegate, ErrInvalidIntersect)))
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	result.Intersected = intersected
+	result.Hidden = hidden
+
+	return result, nil
 }
 
-func createImportMap(imports []*ast.ImportSpec) map[string]*Package {
-	pkgMap := make(map[string]*Package, len(imports))
-	for _, impt := range imports {
-		if impt.Path == nil {
-			continue
+// newParseResult creates the result of a target, applying the options in the tag of the target directive.
+// All invalid options are reported together, each at the position of the target.
+// Nil optional interfaces mean that the elements of the target failed to be created,
+// and the options depending on them are not checked.
+func newParseResult(
+	pkg *packages.Package,
+	pos token.Position,
+	typeName string,
+	tag reflect.StructTag,
+	typeParams []*TypeParam,
+	requireInterfaces, optionalInterfaces, providedInterfaces []*Interface,
+	constraints []*Constraint,
+) (*ParseResult, error) {
+	var errs []error
+	fail := func(err error) {
+		errs = append(errs, NewDiagnostic(pos, err))
+	}
+	knownElements := optionalInterfaces != nil
+
+	funcName := tag.Get("func")
+
+	var maxCases int
+	if strMaxCases, ok := tag.Lookup("cases"); ok {
+		var err error
+		maxCases, err = strconv.Atoi(strMaxCases)
+		if err != nil || maxCases <= 0 {
+			fail(fmt.Errorf("invalid cases(%s) of target(%s): %w", strMaxCases, typeName, ErrInvalidMaxCases))
 		}
-		path, err := strconv.Unquote(impt.Path.Value)
+	}
+
+	var observed []uint64
+	strPatterns, observe := tag.Lookup("observe")
+	switch {
+	case !observe:
+	case len(typeParams) != 0:
+		fail(fmt.Errorf("failed to observe target(%s): %w", typeName, ErrObserveGeneric))
+	case knownElements:
+		dir, err := PackageDir(pkg)
 		if err != nil {
-			log.Printf("invalid import path(%s): %v", impt.Path.Value, err)
-			continue
+			fail(fmt.Errorf("failed to observe target(%s): %w", typeName, err))
+			break
 		}
 
-		if impt.Name == nil {
-			pkgs, err := packages.Load(&packages.Config{
-				Mode: packages.NeedName,
-			}, path)
-			if err != nil {
-				log.Printf("failed to load package(%s): %v", path, err)
-				continue
+		// the package of the target is always scanned
+		patterns := []string{pkg.PkgPath}
+		for pattern := range strings.SplitSeq(strPatterns, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
 			}
+		}
 
-			if len(pkgs) < 1 {
-				log.Printf("failed to load package(%s): no packages", path)
-				continue
-			}
+		observed, err = observeCombinations(dir, patterns, requireInterfaces, optionalInterfaces)
+		if err != nil {
+			fail(fmt.Errorf("failed to observe target(%s): %w", typeName, err))
+		}
+	}
 
-			for _, pkg := range pkgs {
-				name := pkg.Name
-				pkgMap[name] = NewPackage(name, path, false)
+	var fallback Fallback
+	if strFallback, ok := tag.Lookup("fallback"); ok {
+		if !observe {
+			fail(fmt.Errorf("fallback(%s) of target(%s) without observe: %w", strFallback, typeName, ErrInvalidFallback))
+		} else {
+			var err error
+			fallback, err = ParseFallback(strFallback)
+			if err != nil {
+				fail(fmt.Errorf("invalid fallback of target(%s): %w", typeName, err))
 			}
+		}
+	}
+
+	unwrap, err := parseBoolTag(tag, "unwrap", typeName, ErrInvalidUnwrap)
+	if err != nil {
+		fail(err)
+	}
+	// the value is returned as is without the optional and the provided interfaces, so nothing has the Unwrap method
+	if unwrap && knownElements && len(optionalInterfaces) == 0 && len(providedInterfaces) == 0 {
+		fail(fmt.Errorf("unwrap of target(%s) without optional or provided interfaces: %w", typeName, ErrInvalidUnwrap))
+	}
+
+	var lookupDepth int
+	strLookup, lookup := tag.Lookup("lookup")
+	if lookup {
+		if strLookup != lookupUnwrap {
+			fail(fmt.Errorf("unknown lookup(%s) of target(%s), expected %s: %w", strLookup, typeName, lookupUnwrap, ErrInvalidLookup))
+		}
+
+		lookupDepth = DefaultLookupDepth
+	}
+	if strDepth, ok := tag.Lookup("depth"); ok {
+		if !lookup {
+			fail(fmt.Errorf("depth(%s) of target(%s) without lookup: %w", strDepth, typeName, ErrInvalidLookup))
 		} else {
-			name := impt.Name.Name
-			pkgMap[name] = NewPackage(name, path, true)
+			var err error
+			lookupDepth, err = strconv.Atoi(strDepth)
+			if err != nil || lookupDepth <= 0 {
+				fail(fmt.Errorf("invalid depth(%s) of target(%s): %w", strDepth, typeName, ErrInvalidLookup))
+			}
 		}
 	}
 
-	return pkgMap
+	capabilities, err := parseBoolTag(tag, "capabilities", typeName, ErrInvalidCapabilities)
+	if err != nil {
+		fail(err)
+	}
+	if capabilities && knownElements && len(optionalInterfaces) == 0 {
+		fail(fmt.Errorf("capabilities of target(%s) without optional interfaces: %w", typeName, ErrInvalidCapabilities))
+	}
+
+	filter, err := parseBoolTag(tag, "filter", typeName, ErrInvalidFilter)
+	if err != nil {
+		fail(err)
+	}
+	if filter && knownElements && len(optionalInterfaces) == 0 {
+		fail(fmt.Errorf("filter of target(%s) without optional interfaces: %w", typeName, ErrInvalidFilter))
+	}
+
+	value, err := parseBoolTag(tag, "value", typeName, ErrInvalidValue)
+	if err != nil {
+		fail(err)
+	}
+
+	var mode Mode
+	if strMode, ok := tag.Lookup("mode"); ok {
+		var err error
+		mode, err = ParseMode(strMode)
+		if err != nil {
+			fail(fmt.Errorf("invalid mode of target(%s): %w", typeName, err))
+		}
+	}
+
+	base, err := parseBoolTag(tag, "base", typeName, ErrInvalidBase)
+	if err != nil {
+		fail(err)
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &ParseResult{
+		FuncName:           funcName,
+		StructName:         typeName,
+		TypeParams:         typeParams,
+		RequiredInterfaces: requireInterfaces,
+		OptionalInterfaces: optionalInterfaces,
+		ProvidedInterfaces: providedInterfaces,
+		Constraints:        constraints,
+		MaxCases:           maxCases,
+		Observed:           observed,
+		Fallback:           fallback,
+		Scope:              packageScopeNames(pkg),
+		Unwrap:             unwrap,
+		LookupDepth:        lookupDepth,
+		Capabilities:       capabilities,
+		Filter:             filter,
+		Value:              value,
+		Mode:               mode,
+		Base:               base,
+		Pos:                pos,
+	}, nil
 }
 
-func checkIsTargeted(docs []*ast.Comment) (string, bool) {
+// parseBoolTag parses the boolean option of the tag, which is false if the option is not set.
+func parseBoolTag(tag reflect.StructTag, key, typeName string, errInvalid error) (bool, error) {
+	str, ok := tag.Lookup(key)
+	if !ok {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(str)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s(%s) of target(%s): %w", key, str, typeName, errInvalid)
+	}
+
+	return b, nil
+}
+
+func checkIsTargeted(docs []*ast.Comment) (reflect.StructTag, bool) {
 	for _, comment := range docs {
 		if !strings.HasPrefix(comment.Text, targetDirectivePrefix) {
 			continue
 		}
 		annotationTagText := strings.TrimPrefix(comment.Text, targetDirectivePrefix)
-		annotationTag := reflect.StructTag(annotationTagText)
 
-		funcName, ok := annotationTag.Lookup("func")
-		if !ok {
-			return "", true
-		}
-
-		return funcName, true
+		return reflect.StructTag(strings.TrimSpace(annotationTagText)), true
 	}
 
 	return "", false
 }
 
-func createInterfaces(fset *token.FileSet, pkgMap map[string]*Package, fields []*ast.Field) ([]*Interface, []*Interface, error) {
-	requireInterfaces := []*Interface{}
-	optionalInterfaces := []*Interface{}
+// element is an embedded interface or a group of inline methods of a target interface.
+type element struct {
+	intrfc *Interface
+	doc    *ast.CommentGroup
+	// keys are the names referring to the element in the directives
+	keys []string
+}
+
+func createInterfaces(pkg *packages.Package, namer *inlineNamer, fields []*ast.Field) ([]*element, error) {
+	var (
+		elements []*element
+		errs     []error
+	)
+
+	// inline methods are collected into groups, each of which becomes an anonymous interface
+	var inlineFields []*ast.Field
+	flushInlineFields := func() {
+		if len(inlineFields) == 0 {
+			return
+		}
+
+		interfaceValue, err := createInlineInterface(pkg, namer, inlineFields)
+		if err != nil {
+			errs = append(errs, NewDiagnostic(pkg.Fset.Position(inlineFields[0].Pos()), err))
+			inlineFields = nil
+
+			return
+		}
+
+		keys := make([]string, 0, len(inlineFields))
+		for _, field := range inlineFields {
+			for _, name := range field.Names {
+				keys = append(keys, name.Name)
+			}
+		}
+
+		if fn := interfaceValue.invalidMethod(); fn != nil {
+			errs = append(errs, NewDiagnostic(pkg.Fset.Position(fn.Pos()), fmt.Errorf("invalid method(%s): %w", fn.Name(), ErrUnknownType)))
+			inlineFields = nil
+
+			return
+		}
+
+		elements = append(elements, &element{
+			intrfc: interfaceValue,
+			doc:    inlineFields[0].Doc,
+			keys:   keys,
+		})
+		inlineFields = nil
+	}
+
 	for _, field := range fields {
+		pos := pkg.Fset.Position(field.Pos())
 		if field.Type == nil {
-			strField, err := astToString(fset, field)
+			strField, err := astToString(pkg.Fset, field)
 			if err != nil {
-				return nil, nil, errors.New("invalid interface field: no field type")
+				errs = append(errs, NewDiagnostic(pos, errors.New("invalid interface field: no field type")))
+				continue
 			}
-			return nil, nil, fmt.Errorf("invalid interface field(%s): no field type", strField)
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid interface field(%s): no field type", strField)))
+			continue
 		}
 
-		var interfaceValue *Interface
-		switch expr := field.Type.(type) {
-		case *ast.Ident:
-			interfaceValue = NewInterface(nil, expr.Name)
-		case *ast.SelectorExpr:
-			pkgIdent, ok := expr.X.(*ast.Ident)
-			if !ok {
-				strField, err := astToString(fset, field)
-				if err != nil {
-					return nil, nil, errors.New("invalid interface field: invalid field type")
-				}
-				return nil, nil, fmt.Errorf("invalid interface field(%s): invalid field type", strField)
+		if _, ok := field.Type.(*ast.FuncType); ok && len(field.Names) != 0 {
+			if continuesInlineGroup(pkg.Fset, inlineFields, field) {
+				inlineFields = append(inlineFields, field)
+				continue
 			}
 
-			pkg, ok := pkgMap[pkgIdent.Name]
-			if !ok {
-				strField, err := astToString(fset, field)
-				if err != nil {
-					return nil, nil, errors.New("invalid interface field: invalid field type")
-				}
-				return nil, nil, fmt.Errorf("invalid interface field(%s): invalid field type", strField)
-			}
+			flushInlineFields()
+			inlineFields = []*ast.Field{field}
 
-			if expr.Sel == nil {
-				strField, err := astToString(fset, field)
-				if err != nil {
-					return nil, nil, errors.New("invalid interface field: invalid field type")
-				}
-				return nil, nil, fmt.Errorf("invalid interface field(%s): invalid field type", strField)
-			}
+			continue
+		}
+
+		flushInlineFields()
+
+		interfaceValue, err := resolveInterface(pkg.Types, pkg.TypesInfo, field)
+		if err != nil {
+			errs = append(errs, NewDiagnostic(pos, fmt.Errorf("invalid interface field(%s): %w", types.ExprString(field.Type), err)))
+			continue
+		}
+		if fn := interfaceValue.invalidMethod(); fn != nil {
+			errs = append(errs, NewDiagnostic(pkg.Fset.Position(fn.Pos()), fmt.Errorf("invalid method(%s) of interface field(%s): %w", fn.Name(), types.ExprString(field.Type), ErrUnknownType)))
+			continue
+		}
+
+		elements = append(elements, &element{
+			intrfc: interfaceValue,
+			doc:    field.Doc,
+			keys:   []string{types.ExprString(field.Type)},
+		})
+	}
+
+	flushInlineFields()
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return elements, nil
+}
 
-			interfaceValue = NewInterface(pkg, expr.Sel.Name)
+// splitElements splits the elements into the required, the optional and the provided interfaces.
+func splitElements(elements []*element) ([]*Interface, []*Interface, []*Interface) {
+	requireInterfaces := []*Interface{}
+	optionalInterfaces := []*Interface{}
+	var providedInterfaces []*Interface
+	for _, elem := range elements {
+		switch {
+		case isRequired(elem.doc):
+			requireInterfaces = append(requireInterfaces, elem.intrfc)
+		case isProvided(elem.doc):
+			providedInterfaces = append(providedInterfaces, elem.intrfc)
 		default:
-			strField, err := astToString(fset, field)
-			if err != nil {
-				return nil, nil, errors.New("invalid interface field: invalid field type")
-			}
-			return nil, nil, fmt.Errorf("invalid interface field(%s): invalid field type", strField)
+			optionalInterfaces = append(optionalInterfaces, elem.intrfc)
 		}
+	}
 
-		required := false
-		if field.Doc != nil {
-			for _, docs := range field.Doc.List {
-				if strings.HasPrefix(docs.Text, requireDirectivePrefix) {
-					required = true
-					break
-				}
+	return requireInterfaces, optionalInterfaces, providedInterfaces
+}
+
+func isRequired(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, comment := range doc.List {
+		if strings.HasPrefix(comment.Text, requireDirectivePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// continuesInlineGroup reports whether the inline method belongs to the group of the preceding inline methods.
+// A commented inline method starts a group, and the uncommented inline methods on the following lines join it.
+func continuesInlineGroup(fset *token.FileSet, group []*ast.Field, field *ast.Field) bool {
+	if len(group) == 0 || group[0].Doc == nil || field.Doc != nil {
+		return false
+	}
+
+	prevLine := fset.Position(group[len(group)-1].End()).Line
+	line := fset.Position(field.Pos()).Line
+
+	return line == prevLine+1
+}
+
+// inlineNamer names the anonymous interfaces declared for the inline methods of a target.
+type inlineNamer struct {
+	prefix     string
+	typeParams []*TypeParam
+}
+
+func createInlineInterface(pkg *packages.Package, namer *inlineNamer, fields []*ast.Field) (*Interface, error) {
+	var (
+		sb      strings.Builder
+		methods []*Method
+		funcs   []*types.Func
+	)
+	sb.WriteString(namer.prefix)
+	for _, field := range fields {
+		for _, name := range field.Names {
+			fn, ok := pkg.TypesInfo.Defs[name].(*types.Func)
+			if !ok {
+				return nil, fmt.Errorf("invalid interface field(%s): %w", name.Name, ErrUnknownType)
 			}
+
+			sig, ok := fn.Type().(*types.Signature)
+			if !ok {
+				return nil, fmt.Errorf("invalid interface field(%s): %w", name.Name, ErrUnknownType)
+			}
+			sig = types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic())
+
+			sb.WriteString(name.Name)
+			methods = append(methods, NewMethod(name.Name, NewType(sig, pkg.Types)))
+			funcs = append(funcs, types.NewFunc(fn.Pos(), fn.Pkg(), fn.Name(), sig))
 		}
+	}
 
-		if required {
-			requireInterfaces = append(requireInterfaces, interfaceValue)
-		} else {
-			optionalInterfaces = append(optionalInterfaces, interfaceValue)
+	typ := types.NewInterfaceType(funcs, nil)
+	typ.Complete()
+
+	return NewInlineInterface(sb.String(), NewType(typ, pkg.Types), namer.typeParams, methods), nil
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+var (
+	ErrUnknownType     = errors.New("unknown type")
+	ErrNotInterface    = errors.New("not an interface")
+	ErrNotEmbedded     = errors.New("not an embedded interface")
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// resolveInterface resolves an embedded element of a target interface to the interface type it refers to.
+func resolveInterface(localPkg *types.Package, info *types.Info, field *ast.Field) (*Interface, error) {
+	if len(field.Names) != 0 {
+		return nil, ErrNotEmbedded
+	}
+
+	typ := info.TypeOf(field.Type)
+	if typ == nil || typ == types.Typ[types.Invalid] {
+		return nil, ErrUnknownType
+	}
+
+	if _, ok := types.Unalias(typ).Underlying().(*types.Interface); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotInterface, typ)
+	}
+
+	var (
+		obj      *types.TypeName
+		typeArgs *types.TypeList
+	)
+	switch t := typ.(type) {
+	case *types.Alias:
+		obj = t.Obj()
+		typeArgs = t.TypeArgs()
+	case *types.Named:
+		obj = t.Obj()
+		typeArgs = t.TypeArgs()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, typ)
+	}
+
+	var pkg *Package
+	if objPkg := obj.Pkg(); objPkg != nil && objPkg != localPkg {
+		pkg = newPackageFromTypes(objPkg)
+	}
+
+	var args []*Type
+	if typeArgs.Len() != 0 {
+		args = make([]*Type, 0, typeArgs.Len())
+		for i := range typeArgs.Len() {
+			args = append(args, NewType(typeArgs.At(i), localPkg))
 		}
 	}
 
-	return requireInterfaces, optionalInterfaces, nil
+	return NewInterface(pkg, obj.Name(), NewType(typ, localPkg), args...), nil
 }
 
 func astToString(fset *token.FileSet, node ast.Node) (string, error) {
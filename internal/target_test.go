@@ -1,7 +1,8 @@
 package iwrapper
 
 import (
-	"os"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -248,6 +249,532 @@ func TestParseTarget(t *testing.T) {
 				name: "Flusher",
 			}},
 		}},
+	}, {
+		description: "dot importしたinterfaceも正しくパースできる",
+		target:      "dot_import.go",
+		expectedResults: []*ParseResult{{
+			FuncName:   "",
+			StructName: "DotImport",
+			RequiredInterfaces: []*Interface{{
+				pkg: &Package{
+					name: "io",
+					path: "io",
+				},
+				name: "Writer",
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg: &Package{
+					name: "io",
+					path: "io",
+				},
+				name: "StringWriter",
+			}},
+		}},
+	}, {
+		description: "type aliasも正しくパースできる",
+		target:      "alias.go",
+		expectedResults: []*ParseResult{{
+			FuncName:   "",
+			StructName: "Alias",
+			RequiredInterfaces: []*Interface{{
+				pkg: &Package{
+					name: "http",
+					path: "net/http",
+				},
+				name: "ResponseWriter",
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "AliasFlusher",
+			}},
+		}},
+	}, {
+		description: "genericなtargetとinstantiateされたinterfaceも正しくパースできる",
+		target:      "generic.go",
+		expectedResults: []*ParseResult{{
+			FuncName:   "",
+			StructName: "Store",
+			TypeParams: []*TypeParam{{
+				name:       "K",
+				constraint: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+			}, {
+				name:       "V",
+				constraint: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+			}},
+			RequiredInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "Base",
+				typeArgs: []*Type{
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				},
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "Batcher",
+				typeArgs: []*Type{
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				},
+			}},
+		}, {
+			FuncName:   "",
+			StructName: "Instantiated",
+			RequiredInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "Base",
+				typeArgs: []*Type{
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				},
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "Batcher",
+				typeArgs: []*Type{
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				},
+			}},
+		}},
+	}, {
+		description: "inlineのmethodも正しくパースできる",
+		target:      "inline_method.go",
+		expectedResults: []*ParseResult{{
+			FuncName:   "",
+			StructName: "InlineMethod",
+			RequiredInterfaces: []*Interface{{
+				pkg: &Package{
+					name: "http",
+					path: "net/http",
+				},
+				name: "ResponseWriter",
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg: &Package{
+					name: "http",
+					path: "net/http",
+				},
+				name: "Flusher",
+			}, {
+				pkg:  nil,
+				name: "inlineMethodEnableFullDuplex",
+				methods: []*Method{{
+					name:      "EnableFullDuplex",
+					signature: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				}},
+			}, {
+				pkg:  nil,
+				name: "inlineMethodSetReadDeadlineSetWriteDeadline",
+				methods: []*Method{{
+					name:      "SetReadDeadline",
+					signature: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				}, {
+					name:      "SetWriteDeadline",
+					signature: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				}},
+			}},
+		}, {
+			FuncName:   "",
+			StructName: "InlineMethodGeneric",
+			TypeParams: []*TypeParam{{
+				name:       "T",
+				constraint: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+			}},
+			RequiredInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "Base",
+				typeArgs: []*Type{
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+					{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				},
+			}},
+			OptionalInterfaces: []*Interface{{
+				pkg:  nil,
+				name: "inlineMethodGenericPut",
+				methods: []*Method{{
+					name:      "Put",
+					signature: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				}},
+				typeParams: []*TypeParam{{
+					name:       "T",
+					constraint: &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"},
+				}},
+			}},
+		}},
+	}, {
+		description: "constraintも正しくパースできる",
+		target:      "constraints.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{
+				name: "http",
+				path: "net/http",
+			}
+			io := &Package{
+				name: "io",
+				path: "io",
+			}
+			hijacker := &Interface{pkg: http, name: "Hijacker"}
+			closeNotifier := &Interface{pkg: http, name: "CloseNotifier"}
+			readerFrom := &Interface{pkg: io, name: "ReaderFrom"}
+			stringWriter := &Interface{pkg: io, name: "StringWriter"}
+			pusher := &Interface{pkg: http, name: "Pusher"}
+			local := &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"}
+			typeParams := []*TypeParam{
+				{name: "K", constraint: local},
+				{name: "V", constraint: local},
+			}
+			lister := &Interface{name: "Lister", typeArgs: []*Type{local, local}}
+			batcher := &Interface{name: "Batcher", typeArgs: []*Type{local, local}}
+			counter := &Interface{name: "Counter", typeArgs: []*Type{local, local}}
+
+			return []*ParseResult{{
+				FuncName:           "",
+				StructName:         "Constraints",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{hijacker, closeNotifier, readerFrom, stringWriter, pusher},
+				Constraints: []*Constraint{{
+					Kind:       ConstraintImplies,
+					Interfaces: []*Interface{readerFrom, stringWriter},
+				}, {
+					Kind:       ConstraintExcludes,
+					Interfaces: []*Interface{pusher, hijacker},
+				}, {
+					Kind:       ConstraintGroup,
+					Interfaces: []*Interface{hijacker, closeNotifier},
+				}},
+			}, {
+				StructName:         "constraintStore",
+				TypeParams:         typeParams,
+				RequiredInterfaces: []*Interface{{name: "Base", typeArgs: []*Type{local, local}}},
+				OptionalInterfaces: []*Interface{lister, batcher, counter},
+				Constraints: []*Constraint{{
+					Kind:       ConstraintImplies,
+					Interfaces: []*Interface{lister, batcher},
+				}, {
+					Kind:       ConstraintExcludes,
+					Interfaces: []*Interface{counter, lister},
+				}},
+			}}
+		}(),
+	}, {
+		description: "observeで実在する型が実装する組み合わせを収集できる",
+		target:      "observed.go",
+		expectedResults: func() []*ParseResult {
+			writer := &Interface{name: "ObservedWriter"}
+			optionalInterfaces := []*Interface{
+				{name: "ObservedFlusher"},
+				{name: "ObservedCloser"},
+				{name: "ObservedPusher"},
+			}
+
+			return []*ParseResult{{
+				StructName:         "Observed",
+				RequiredInterfaces: []*Interface{writer},
+				OptionalInterfaces: optionalInterfaces,
+				Observed:           []uint64{0b000, 0b001, 0b011},
+			}, {
+				StructName:         "ObservedRequired",
+				RequiredInterfaces: []*Interface{writer},
+				OptionalInterfaces: optionalInterfaces,
+				Observed:           []uint64{0b000, 0b001, 0b011},
+				Fallback:           FallbackRequired,
+			}}
+		}(),
+	}, {
+		description: "同名の別packageのinterfaceをimport pathで区別できる",
+		target:      "import_collision.go",
+		expectedResults: []*ParseResult{{
+			StructName:         "ImportCollision",
+			RequiredInterfaces: []*Interface{{pkg: &Package{name: "io", path: "io"}, name: "Reader"}},
+			OptionalInterfaces: []*Interface{
+				{pkg: &Package{name: "rand", path: "math/rand"}, name: "Source"},
+				{pkg: &Package{name: "rand", path: "math/rand/v2"}, name: "Source"},
+			},
+		}},
+	}, {
+		description: "lookupとdepthを指定できる",
+		target:      "lookup.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+
+			return []*ParseResult{{
+				StructName:         "Lookup",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}, {pkg: io, name: "StringWriter"}},
+				LookupDepth:        DefaultLookupDepth,
+			}, {
+				StructName:         "LookupBounded",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}, {pkg: io, name: "ReaderFrom"}},
+				MaxCases:           2,
+				Unwrap:             true,
+				LookupDepth:        4,
+			}}
+		}(),
+	}, {
+		description: "capabilitiesを指定できる",
+		target:      "capabilities.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+
+			return []*ParseResult{{
+				StructName:         "Capabilities",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}},
+				Capabilities:       true,
+			}, {
+				StructName:         "CapabilitiesLookup",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: io, name: "ReaderFrom"}},
+				Unwrap:             true,
+				LookupDepth:        DefaultLookupDepth,
+				Capabilities:       true,
+			}}
+		}(),
+	}, {
+		description: "baseを指定できる",
+		target:      "base.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+			local := &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"}
+			typeParams := []*TypeParam{
+				{name: "K", constraint: local},
+				{name: "V", constraint: local},
+			}
+
+			return []*ParseResult{{
+				StructName:         "Passthrough",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{
+					{pkg: http, name: "Flusher"},
+					{pkg: http, name: "Hijacker"},
+					{pkg: io, name: "StringWriter"},
+					{pkg: io, name: "Writer"},
+					{name: "passthroughPrintf", methods: []*Method{{name: "Printf", signature: local}}},
+					{name: "passthroughValue", methods: []*Method{{name: "Value", signature: local}}},
+				},
+				Base: true,
+			}, {
+				StructName:         "passthroughStore",
+				TypeParams:         typeParams,
+				RequiredInterfaces: []*Interface{{name: "Base", typeArgs: []*Type{local, local}}},
+				OptionalInterfaces: []*Interface{{name: "Batcher", typeArgs: []*Type{local, local}}},
+				Base:               true,
+			}, {
+				StructName:         "PassthroughProvide",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Hijacker"}},
+				ProvidedInterfaces: []*Interface{{pkg: http, name: "Flusher"}},
+				Base:               true,
+			}}
+		}(),
+	}, {
+		description: "modeを指定できる",
+		target:      "delegate.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+
+			return []*ParseResult{{
+				StructName:         "Delegate",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}},
+				Mode:               ModeDelegate,
+			}, {
+				StructName:         "DelegateLookup",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: io, name: "ReaderFrom"}, {pkg: io, name: "StringWriter"}},
+				MaxCases:           2,
+				LookupDepth:        DefaultLookupDepth,
+				Capabilities:       true,
+				Mode:               ModeDelegate,
+			}}
+		}(),
+	}, {
+		description: "intersectを指定できる",
+		target:      "intersect.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+			hijacker := &Interface{pkg: http, name: "Hijacker"}
+
+			return []*ParseResult{{
+				StructName:         "Intersect",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}},
+				Mode:               ModeIntersect,
+			}, {
+				StructName:         "IntersectDelegate",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, hijacker, {pkg: io, name: "ReaderFrom"}},
+				LookupDepth:        DefaultLookupDepth,
+				Mode:               ModeDelegate,
+				Intersected:        []*Interface{hijacker},
+			}}
+		}(),
+	}, {
+		description: "provideを指定できる",
+		target:      "provide.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+
+			return []*ParseResult{{
+				StructName:         "Provide",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Hijacker"}, {pkg: io, name: "ReaderFrom"}},
+				ProvidedInterfaces: []*Interface{{pkg: http, name: "Flusher"}},
+				Base:               true,
+			}, {
+				StructName:         "ProvideOnly",
+				RequiredInterfaces: []*Interface{{pkg: io, name: "Reader"}},
+				OptionalInterfaces: []*Interface{},
+				ProvidedInterfaces: []*Interface{{pkg: io, name: "Seeker"}},
+				Mode:               ModeDelegate,
+			}}
+		}(),
+	}, {
+		description: "hideを指定できる",
+		target:      "hide.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+			flusher := &Interface{pkg: http, name: "Flusher"}
+			closeNotifier := &Interface{pkg: http, name: "CloseNotifier"}
+			hijacker := &Interface{pkg: http, name: "Hijacker"}
+			readerFrom := &Interface{pkg: io, name: "ReaderFrom"}
+
+			return []*ParseResult{{
+				StructName:         "Hide",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{flusher, closeNotifier, hijacker, readerFrom},
+				Capabilities:       true,
+				Hidden:             []*Interface{closeNotifier, readerFrom},
+			}, {
+				StructName:         "HideBounded",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{flusher, hijacker, {pkg: io, name: "StringWriter"}},
+				Constraints: []*Constraint{{
+					Kind:       ConstraintImplies,
+					Interfaces: []*Interface{flusher, hijacker},
+				}},
+				MaxCases: 2,
+				Mode:     ModeDelegate,
+				Hidden:   []*Interface{flusher},
+			}}
+		}(),
+	}, {
+		description: "filterを指定できる",
+		target:      "filter.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+			local := &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"}
+			typeParams := []*TypeParam{
+				{name: "K", constraint: local},
+				{name: "V", constraint: local},
+			}
+
+			return []*ParseResult{{
+				StructName:         "Filter",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}, {pkg: io, name: "ReaderFrom"}},
+				Filter:             true,
+			}, {
+				StructName:         "filterStore",
+				TypeParams:         typeParams,
+				RequiredInterfaces: []*Interface{{name: "Base", typeArgs: []*Type{local, local}}},
+				OptionalInterfaces: []*Interface{{name: "Batcher", typeArgs: []*Type{local, local}}},
+				LookupDepth:        DefaultLookupDepth,
+				Capabilities:       true,
+				Filter:             true,
+				Mode:               ModeDelegate,
+			}}
+		}(),
+	}, {
+		description: "valueを指定できる",
+		target:      "value.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+			local := &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"}
+			typeParams := []*TypeParam{
+				{name: "K", constraint: local},
+				{name: "W", constraint: local},
+			}
+			batcher := &Interface{name: "Batcher", typeArgs: []*Type{local, local}}
+
+			return []*ParseResult{{
+				StructName:         "Value",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}, {pkg: io, name: "ReaderFrom"}},
+				Value:              true,
+			}, {
+				StructName:         "valueStore",
+				TypeParams:         typeParams,
+				RequiredInterfaces: []*Interface{{name: "Base", typeArgs: []*Type{local, local}}},
+				OptionalInterfaces: []*Interface{batcher},
+				Unwrap:             true,
+				LookupDepth:        DefaultLookupDepth,
+				Filter:             true,
+				Value:              true,
+				Mode:               ModeDelegate,
+				Intersected:        []*Interface{batcher},
+			}}
+		}(),
+	}, {
+		description: "unwrapを指定できる",
+		target:      "unwrap.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			local := &Type{localPath: "github.com/mazrean/iwrapper/internal/testdata"}
+			typeParams := []*TypeParam{
+				{name: "K", constraint: local},
+				{name: "V", constraint: local},
+			}
+
+			return []*ParseResult{{
+				StructName:         "Unwrappable",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}},
+				Unwrap:             true,
+			}, {
+				StructName:         "unwrappableStore",
+				TypeParams:         typeParams,
+				RequiredInterfaces: []*Interface{{name: "Base", typeArgs: []*Type{local, local}}},
+				OptionalInterfaces: []*Interface{{name: "Batcher", typeArgs: []*Type{local, local}}},
+				Unwrap:             true,
+			}}
+		}(),
+	}, {
+		description: "wrapでlocalなinterfaceなしに宣言できる",
+		target:      "wrap.go",
+		expectedResults: func() []*ParseResult {
+			http := &Package{name: "http", path: "net/http"}
+			io := &Package{name: "io", path: "io"}
+
+			return []*ParseResult{{
+				StructName:         "ResponseWriter",
+				RequiredInterfaces: []*Interface{{pkg: http, name: "ResponseWriter"}},
+				OptionalInterfaces: []*Interface{{pkg: http, name: "Flusher"}, {pkg: http, name: "Hijacker"}},
+				Declare:            true,
+			}, {
+				FuncName:           "WrapWriter",
+				StructName:         "WrappedWriter",
+				RequiredInterfaces: []*Interface{{pkg: io, name: "Writer"}},
+				OptionalInterfaces: []*Interface{{pkg: io, name: "StringWriter"}, {pkg: &Package{name: "expvar", path: "expvar"}, name: "Var"}},
+				Declare:            true,
+			}, {
+				StructName:         "WrappedReadCloser",
+				RequiredInterfaces: []*Interface{{pkg: io, name: "Reader"}, {pkg: io, name: "Closer"}},
+				OptionalInterfaces: []*Interface{{pkg: io, name: "WriterTo"}},
+				Declare:            true,
+			}}
+		}(),
 	}}
 
 	for _, testCase := range testCases {
@@ -255,12 +782,7 @@ func TestParseTarget(t *testing.T) {
 		t.Run(testCase.description, func(t *testing.T) {
 			//t.Parallel()
 
-			target, err := os.Open("testdata/" + testCase.target)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			pkgName, results, err := ParseTarget(target)
+			pkgName, results, err := ParseTarget("testdata/" + testCase.target)
 			if err != nil {
 				t.Error(err)
 			}
@@ -279,3 +801,213 @@ func TestParseTarget(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTargetInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		target      string
+		expectedErr error
+	}{{
+		description: "structはエラー",
+		target:      "struct.go",
+		expectedErr: ErrNotInterface,
+	}, {
+		description: "interfaceでないaliasはエラー",
+		target:      "non_interface_alias.go",
+		expectedErr: ErrNotInterface,
+	}, {
+		description: "constraintで存在しない要素を参照するとエラー",
+		target:      "unknown_constraint.go",
+		expectedErr: ErrInvalidConstraint,
+	}, {
+		description: "存在しない名前はエラー",
+		target:      "unknown.go",
+		expectedErr: ErrUnknownType,
+	}, {
+		description: "observeなしのfallbackはエラー",
+		target:      "fallback_without_observe.go",
+		expectedErr: ErrInvalidFallback,
+	}, {
+		description: "genericなtargetのobserveはエラー",
+		target:      "observe_generic.go",
+		expectedErr: ErrObserveGeneric,
+	}, {
+		description: "wrapで宣言済みの名前はエラー",
+		target:      "wrap_declared.go",
+		expectedErr: ErrInvalidWrap,
+	}, {
+		description: "wrapで他のツールの生成ファイルで宣言済みの名前はエラー",
+		target:      "wrap_declared_generated.go",
+		expectedErr: ErrInvalidWrap,
+	}, {
+		description: "wrapで存在しないinterfaceはエラー",
+		target:      "wrap_unknown.go",
+		expectedErr: ErrUnknownType,
+	}, {
+		description: "unwrapがboolでなければエラー",
+		target:      "unwrap_invalid.go",
+		expectedErr: ErrInvalidUnwrap,
+	}, {
+		description: "optionalなinterfaceもprovideしたinterfaceもないunwrapはエラー",
+		target:      "unwrap_without_optional.go",
+		expectedErr: ErrInvalidUnwrap,
+	}, {
+		description: "未知のlookupはエラー",
+		target:      "lookup_invalid.go",
+		expectedErr: ErrInvalidLookup,
+	}, {
+		description: "lookupなしのdepthはエラー",
+		target:      "depth_without_lookup.go",
+		expectedErr: ErrInvalidLookup,
+	}, {
+		description: "capabilitiesがboolでなければエラー",
+		target:      "capabilities_invalid.go",
+		expectedErr: ErrInvalidCapabilities,
+	}, {
+		description: "optionalなinterfaceなしのcapabilitiesはエラー",
+		target:      "capabilities_without_optional.go",
+		expectedErr: ErrInvalidCapabilities,
+	}, {
+		description: "baseがboolでなければエラー",
+		target:      "base_invalid.go",
+		expectedErr: ErrInvalidBase,
+	}, {
+		description: "未知のmodeはエラー",
+		target:      "mode_invalid.go",
+		expectedErr: ErrInvalidMode,
+	}, {
+		description: "implementモードのintersectはエラー",
+		target:      "intersect_implement.go",
+		expectedErr: ErrInvalidIntersect,
+	}, {
+		description: "requiredなinterfaceのintersectはエラー",
+		target:      "intersect_required.go",
+		expectedErr: ErrInvalidIntersect,
+	}, {
+		description: "requiredなinterfaceのprovideはエラー",
+		target:      "provide_required.go",
+		expectedErr: ErrInvalidProvide,
+	}, {
+		description: "requiredなinterfaceのhideはエラー",
+		target:      "hide_required.go",
+		expectedErr: ErrInvalidHide,
+	}, {
+		description: "optionalなinterfaceなしのfilterはエラー",
+		target:      "filter_without_optional.go",
+		expectedErr: ErrInvalidFilter,
+	}, {
+		description: "valueがboolでなければエラー",
+		target:      "value_invalid.go",
+		expectedErr: ErrInvalidValue,
+	}, {
+		description: "型検査に失敗した型を含むmethodはエラー",
+		target:      "invalid_method_type.go",
+		expectedErr: ErrUnknownType,
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := ParseTarget("testdata/invalid/" + testCase.target)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("error: expected %v, got %v", testCase.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseTargetNoFile(t *testing.T) {
+	t.Parallel()
+
+	path := "testdata/missing.go"
+	_, _, err := ParseTarget(path)
+	if !errors.Is(err, ErrNoFile) {
+		t.Fatalf("error: expected %v, got %v", ErrNoFile, err)
+	}
+
+	// the diagnostic names the file, which may be misspelled
+	if !strings.Contains(err.Error(), path) {
+		t.Errorf("error: expected the path(%s), got %v", path, err)
+	}
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Parallel()
+
+	pkgs, err := LoadPackages("./testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pkgs) != 1 {
+		t.Fatalf("pkgs: expected %d, got %d", 1, len(pkgs))
+	}
+
+	results, err := ParsePackage(pkgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedStructNames := []string{
+		"Alias",
+		"Passthrough",
+		"passthroughStore",
+		"PassthroughProvide",
+		"Capabilities",
+		"CapabilitiesLookup",
+		"Collision",
+		"Constraints",
+		"constraintStore",
+		"Delegate",
+		"DelegateLookup",
+		"DotImport",
+		"Filter",
+		"filterStore",
+		"FuncName",
+		"Store",
+		"Instantiated",
+		"Hide",
+		"HideBounded",
+		"ImportCollision",
+		"InlineMethod",
+		"InlineMethodGeneric",
+		"Intersect",
+		"IntersectDelegate",
+		"Lookup",
+		"LookupBounded",
+		"ManyOptional",
+		"MultiOptional",
+		"MultiRequire",
+		"MultiTarget1",
+		"MultiTarget2",
+		"MultiTargetInBracket1",
+		"MultiTargetInBracket2",
+		"Normal",
+		"Observed",
+		"ObservedRequired",
+		"OtherFileDeclare",
+		"Provide",
+		"ProvideOnly",
+		"TypeInBracketInsideComment",
+		"TypeInBracketOutsideComment",
+		"Unwrappable",
+		"unwrappableStore",
+		"Value",
+		"valueStore",
+		"ResponseWriter",
+		"WrappedWriter",
+		"WrappedReadCloser",
+	}
+
+	structNames := make([]string, 0, len(results))
+	for _, result := range results {
+		structNames = append(structNames, result.StructName)
+	}
+
+	if diff := diff(structNames, expectedStructNames); diff != "" {
+		t.Errorf("struct names diff: %s", diff)
+	}
+}
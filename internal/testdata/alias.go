@@ -0,0 +1,14 @@
+package testdata
+
+import (
+	"net/http"
+)
+
+type AliasFlusher = http.Flusher
+
+//iwrapper:target
+type Alias interface {
+	//iwrapper:require
+	http.ResponseWriter
+	AliasFlusher
+}
@@ -0,0 +1,36 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target base:"true"
+type Passthrough interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	io.StringWriter
+	io.Writer
+	// Printf writes the formatted string.
+	Printf(string, ...any) (int, error)
+	// Value conflicts with the field of the base struct.
+	Value() int
+}
+
+//iwrapper:target base:"true"
+type passthroughStore[K comparable, V any] interface {
+	//iwrapper:require
+	Base[K, V]
+	Batcher[K, V]
+}
+
+//iwrapper:target base:"true"
+type PassthroughProvide interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:provide
+	http.Flusher
+	http.Hijacker
+}
@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target capabilities:"true"
+type Capabilities interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+}
+
+//iwrapper:target capabilities:"true" lookup:"unwrap" unwrap:"true"
+type CapabilitiesLookup interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	io.ReaderFrom
+}
@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+type StringFlusher interface {
+	WriteString(s string) (n int, err error)
+	Flush()
+}
+
+//iwrapper:target
+type Collision interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:require
+	io.Writer
+	io.StringWriter
+	StringFlusher
+	http.Flusher
+}
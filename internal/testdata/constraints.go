@@ -0,0 +1,40 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target
+type Constraints interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:group hijack
+	http.Hijacker
+	//iwrapper:group hijack
+	http.CloseNotifier
+	//iwrapper:implies io.StringWriter
+	io.ReaderFrom
+	io.StringWriter
+	//iwrapper:excludes http.Hijacker
+	http.Pusher
+}
+
+type Lister[K comparable, V any] interface {
+	List() map[K]V
+}
+
+type Counter[K comparable, V any] interface {
+	Count() int
+}
+
+//iwrapper:target
+type constraintStore[K comparable, V any] interface {
+	//iwrapper:require
+	Base[K, V]
+	//iwrapper:implies Batcher[K, V]
+	Lister[K, V]
+	Batcher[K, V]
+	//iwrapper:excludes Lister[K,V]
+	Counter[K, V]
+}
@@ -0,0 +1,23 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target mode:"delegate"
+type Delegate interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+}
+
+//iwrapper:target mode:"delegate" lookup:"unwrap" capabilities:"true" cases:"2"
+type DelegateLookup interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	io.ReaderFrom
+	io.StringWriter
+}
@@ -0,0 +1,12 @@
+package testdata
+
+import (
+	. "io"
+)
+
+//iwrapper:target
+type DotImport interface {
+	//iwrapper:require
+	Writer
+	StringWriter
+}
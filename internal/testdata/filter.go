@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target filter:"true"
+type Filter interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	io.ReaderFrom
+}
+
+//iwrapper:target filter:"true" capabilities:"true" mode:"delegate" lookup:"unwrap"
+type filterStore[K comparable, V any] interface {
+	//iwrapper:require
+	Base[K, V]
+	Batcher[K, V]
+}
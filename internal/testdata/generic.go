@@ -0,0 +1,27 @@
+package testdata
+
+import (
+	"time"
+)
+
+type Base[K comparable, V any] interface {
+	Get(K) (V, bool)
+}
+
+type Batcher[K comparable, V any] interface {
+	GetBatch([]K) []V
+}
+
+//iwrapper:target
+type Store[K comparable, V any] interface {
+	//iwrapper:require
+	Base[K, V]
+	Batcher[K, V]
+}
+
+//iwrapper:target
+type Instantiated interface {
+	//iwrapper:require
+	Base[string, int]
+	Batcher[string, time.Time]
+}
@@ -0,0 +1,29 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target capabilities:"true"
+type Hide interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	//iwrapper:hide
+	http.CloseNotifier
+	http.Hijacker
+	//iwrapper:hide
+	io.ReaderFrom
+}
+
+//iwrapper:target mode:"delegate" cases:"2"
+type HideBounded interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:hide
+	//iwrapper:implies http.Hijacker
+	http.Flusher
+	http.Hijacker
+	io.StringWriter
+}
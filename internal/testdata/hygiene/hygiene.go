@@ -0,0 +1,40 @@
+package hygiene
+
+import (
+	"io"
+	stdhttp "net/http"
+
+	"github.com/mazrean/iwrapper/internal/testdata/hygiene/ok"
+	"github.com/mazrean/iwrapper/internal/testdata/hygiene/v"
+)
+
+// the package level names collide with the identifiers of the generated code
+var (
+	i1      = 1
+	wrapper = "wrapper"
+)
+
+type wrapped interface {
+	Wrapped()
+}
+
+type io_ interface {
+	IO()
+}
+
+// the package level name collides with the name of the imported package
+type http interface {
+	HTTP()
+}
+
+//iwrapper:target
+type i0 interface {
+	//iwrapper:require
+	io.Writer
+	ok.Checker
+	v.Valuer
+	wrapped
+	io_
+	http
+	stdhttp.Flusher
+}
@@ -0,0 +1,5 @@
+package ok
+
+type Checker interface {
+	Check() bool
+}
@@ -0,0 +1,5 @@
+package v
+
+type Valuer interface {
+	Value() int
+}
@@ -0,0 +1,15 @@
+package testdata
+
+import (
+	"io"
+	"math/rand"
+	randv2 "math/rand/v2"
+)
+
+//iwrapper:target
+type ImportCollision interface {
+	//iwrapper:require
+	io.Reader
+	rand.Source
+	randv2.Source
+}
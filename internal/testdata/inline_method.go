@@ -0,0 +1,24 @@
+package testdata
+
+import (
+	"net/http"
+	"time"
+)
+
+//iwrapper:target
+type InlineMethod interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	EnableFullDuplex() error
+	// deadlines
+	SetReadDeadline(deadline time.Time) error
+	SetWriteDeadline(deadline time.Time) error
+}
+
+//iwrapper:target
+type InlineMethodGeneric[T any] interface {
+	//iwrapper:require
+	Base[string, T]
+	Put(string, T) error
+}
@@ -0,0 +1,24 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target mode:"intersect"
+type Intersect interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+}
+
+//iwrapper:target mode:"delegate" lookup:"unwrap"
+type IntersectDelegate interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	//iwrapper:intersect
+	http.Hijacker
+	io.ReaderFrom
+}
@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target base:"yes"
+type BaseInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target capabilities:"yes"
+type CapabilitiesInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,11 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target capabilities:"true"
+type CapabilitiesWithoutOptional interface {
+	//iwrapper:require
+	http.ResponseWriter
+}
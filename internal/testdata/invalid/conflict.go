@@ -0,0 +1,16 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+type ConflictWriter interface {
+	Write(s string) error
+}
+
+//iwrapper:target
+type Conflict interface {
+	//iwrapper:require
+	http.ResponseWriter
+	ConflictWriter
+}
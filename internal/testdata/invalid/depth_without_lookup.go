@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target depth:"4"
+type DepthWithoutLookup interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,10 @@
+package invalid
+
+import "net/http"
+
+//iwrapper:target fallback:"required"
+type FallbackWithoutObserve interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
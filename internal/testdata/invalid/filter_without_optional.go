@@ -0,0 +1,11 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target filter:"true"
+type FilterWithoutOptional interface {
+	//iwrapper:require
+	http.ResponseWriter
+}
@@ -0,0 +1,13 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target
+type HideRequired interface {
+	//iwrapper:require
+	//iwrapper:hide
+	http.ResponseWriter
+	http.Flusher
+}
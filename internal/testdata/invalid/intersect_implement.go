@@ -0,0 +1,13 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target
+type IntersectImplement interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:intersect
+	http.Hijacker
+}
@@ -0,0 +1,13 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target mode:"delegate"
+type IntersectRequired interface {
+	//iwrapper:require
+	//iwrapper:intersect
+	http.ResponseWriter
+	http.Hijacker
+}
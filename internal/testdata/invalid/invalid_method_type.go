@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target
+type InvalidMethodType interface {
+	//iwrapper:require
+	http.ResponseWriter
+	SetDeadline(t Undefined) error
+}
@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target lookup:"embed"
+type LookupInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target mode:"loose"
+type ModeInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
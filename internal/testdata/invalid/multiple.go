@@ -0,0 +1,24 @@
+package invalid
+
+import "net/http"
+
+//iwrapper:target
+type Multiple interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Unknown
+	Missing
+}
+
+//iwrapper:target cases:"0"
+type InvalidCases interface {
+	//iwrapper:require
+	http.ResponseWriter
+}
+
+//iwrapper:target cases:"x" filter:"x"
+type InvalidElementAndOptions interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Unknown
+}
@@ -0,0 +1,14 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+type NonInterfaceAlias = http.Request
+
+//iwrapper:target
+type NonInterfaceAliasElement interface {
+	//iwrapper:require
+	http.ResponseWriter
+	NonInterfaceAlias
+}
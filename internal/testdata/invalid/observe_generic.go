@@ -0,0 +1,8 @@
+package invalid
+
+//iwrapper:target observe:"."
+type ObserveGeneric[T any] interface {
+	//iwrapper:require
+	Get() T
+	Set(v T)
+}
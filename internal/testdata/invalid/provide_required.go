@@ -0,0 +1,13 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target
+type ProvideRequired interface {
+	//iwrapper:require
+	//iwrapper:provide
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,14 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+type Struct struct{}
+
+//iwrapper:target
+type StructElement interface {
+	//iwrapper:require
+	http.ResponseWriter
+	Struct
+}
@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target
+type UnknownElement interface {
+	//iwrapper:require
+	http.ResponseWriter
+	Unknown
+}
@@ -0,0 +1,14 @@
+package invalid
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target
+type UnknownConstraint interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:implies io.WriterTo
+	io.ReaderFrom
+}
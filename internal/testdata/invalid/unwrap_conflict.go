@@ -0,0 +1,16 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+type Unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+//iwrapper:target unwrap:"true"
+type UnwrapConflict interface {
+	//iwrapper:require
+	http.ResponseWriter
+	Unwrapper
+}
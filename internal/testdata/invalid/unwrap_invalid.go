@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target unwrap:"yes"
+type UnwrapInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
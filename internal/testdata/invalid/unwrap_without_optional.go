@@ -0,0 +1,11 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target unwrap:"true"
+type UnwrapWithoutOptional interface {
+	//iwrapper:require
+	http.ResponseWriter
+}
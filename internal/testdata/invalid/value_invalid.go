@@ -0,0 +1,12 @@
+package invalid
+
+import (
+	"net/http"
+)
+
+//iwrapper:target value:"yes"
+type ValueInvalid interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+}
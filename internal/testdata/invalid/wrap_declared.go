@@ -0,0 +1,10 @@
+package invalid
+
+import "net/http"
+
+type DeclaredResponseWriter interface {
+	http.ResponseWriter
+}
+
+//iwrapper:wrap net/http.ResponseWriter optional:"net/http.Flusher" name:"DeclaredResponseWriter"
+var _ http.ResponseWriter
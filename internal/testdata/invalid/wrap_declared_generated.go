@@ -0,0 +1,6 @@
+package invalid
+
+import "net/http"
+
+//iwrapper:wrap net/http.ResponseWriter optional:"net/http.Flusher" name:"ProtoResponseWriter"
+var _ http.ResponseWriter
@@ -0,0 +1,4 @@
+package invalid
+
+//iwrapper:wrap net/http.Unknown
+var _ = 0
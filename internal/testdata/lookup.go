@@ -0,0 +1,24 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target lookup:"unwrap"
+type Lookup interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	io.StringWriter
+}
+
+//iwrapper:target lookup:"unwrap" depth:"4" unwrap:"true" cases:"2"
+type LookupBounded interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	io.ReaderFrom
+}
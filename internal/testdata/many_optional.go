@@ -0,0 +1,22 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target cases:"16"
+type ManyOptional interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+	http.CloseNotifier
+	http.Pusher
+	io.ReaderFrom
+	io.StringWriter
+	io.Closer
+	io.Seeker
+	io.ReaderAt
+	io.WriterTo
+}
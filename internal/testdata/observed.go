@@ -0,0 +1,56 @@
+package testdata
+
+//iwrapper:target observe:"."
+type Observed interface {
+	//iwrapper:require
+	ObservedWriter
+	ObservedFlusher
+	ObservedCloser
+	ObservedPusher
+}
+
+//iwrapper:target observe:"." fallback:"required"
+type ObservedRequired interface {
+	//iwrapper:require
+	ObservedWriter
+	ObservedFlusher
+	ObservedCloser
+	ObservedPusher
+}
+
+type ObservedWriter interface {
+	ObservedWrite(p []byte) (int, error)
+}
+
+type ObservedFlusher interface {
+	ObservedFlush()
+}
+
+type ObservedCloser interface {
+	ObservedClose() error
+}
+
+type ObservedPusher interface {
+	ObservedPush(target string) error
+}
+
+// parameter names of the methods may differ from the interfaces.
+type observedPlainWriter struct{}
+
+func (observedPlainWriter) ObservedWrite(data []byte) (n int, err error) { return len(data), nil }
+
+type observedFlushWriter struct{}
+
+func (observedFlushWriter) ObservedWrite(data []byte) (n int, err error) { return len(data), nil }
+func (observedFlushWriter) ObservedFlush()                               {}
+
+type observedCloseWriter struct{}
+
+func (observedCloseWriter) ObservedWrite(data []byte) (n int, err error) { return len(data), nil }
+func (observedCloseWriter) ObservedFlush()                               {}
+func (*observedCloseWriter) ObservedClose() error                        { return nil }
+
+// observedPusher does not implement ObservedWriter, so it is not observed.
+type observedPusher struct{}
+
+func (observedPusher) ObservedPush(string) error { return nil }
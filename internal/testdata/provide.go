@@ -0,0 +1,24 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target base:"true"
+type Provide interface {
+	//iwrapper:require
+	http.ResponseWriter
+	//iwrapper:provide
+	http.Flusher
+	http.Hijacker
+	io.ReaderFrom
+}
+
+//iwrapper:target mode:"delegate"
+type ProvideOnly interface {
+	//iwrapper:require
+	io.Reader
+	//iwrapper:provide
+	io.Seeker
+}
@@ -0,0 +1,20 @@
+package testdata
+
+import (
+	"net/http"
+)
+
+//iwrapper:target unwrap:"true"
+type Unwrappable interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+}
+
+//iwrapper:target unwrap:"true"
+type unwrappableStore[K comparable, V any] interface {
+	//iwrapper:require
+	Base[K, V]
+	Batcher[K, V]
+}
@@ -0,0 +1,23 @@
+package testdata
+
+import (
+	"io"
+	"net/http"
+)
+
+//iwrapper:target value:"true"
+type Value interface {
+	//iwrapper:require
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	io.ReaderFrom
+}
+
+//iwrapper:target value:"true" filter:"true" mode:"delegate" lookup:"unwrap" unwrap:"true"
+type valueStore[K comparable, W any] interface {
+	//iwrapper:require
+	Base[K, W]
+	//iwrapper:intersect
+	Batcher[K, W]
+}
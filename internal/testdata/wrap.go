@@ -0,0 +1,12 @@
+//iwrapper:wrap net/http.ResponseWriter optional:"net/http.Flusher,net/http.Hijacker"
+package testdata
+
+import "io"
+
+//iwrapper:wrap io.Writer optional:"io.StringWriter,expvar.Var" name:"WrappedWriter" func:"WrapWriter"
+var _ io.Writer
+
+// the required interfaces may be separated with spaces after the commas
+//
+//iwrapper:wrap io.Reader, io.Closer optional:"io.WriterTo" name:"WrappedReadCloser"
+var _ io.Reader
@@ -0,0 +1,238 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidUnwrap = errors.New("invalid unwrap")
+)
+
+// unwrapMethodName is the name of the method returning the wrapped value, following the convention of
+// errors.Unwrap and http.ResponseController.
+const unwrapMethodName = "Unwrap"
+
+// unwrapFuncName returns the name of the function retrieving the innermost value of the target,
+// e.g. UnwrapResponseWriter, or unwrapResponseWriter for an unexported target.
+func unwrapFuncName(structName string) string {
+	r, _ := utf8.DecodeRuneInString(structName)
+	if unicode.IsUpper(r) {
+		return unwrapMethodName + structName
+	}
+
+	return "unwrap" + upperFirst(structName)
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// checkUnwrapConflict reports the interfaces which already have the Unwrap method,
+// since the embedded method would make the Unwrap method of the wrapper ambiguous.
+func checkUnwrapConflict(interfaces []*Interface) error {
+	for _, intrfc := range interfaces {
+		for _, fn := range intrfc.methodFuncs() {
+			if fn.Name() == unwrapMethodName {
+				return fmt.Errorf("%w: method %s of %s(%s) conflicts with the generated method", ErrMethodConflict, fn.Name(), intrfc, fn.Type())
+			}
+		}
+	}
+
+	return nil
+}
+
+// unwrapperName returns the name of the type embedded in the wrappers of the target to provide the Unwrap method.
+func (conf *GenerateConfig) unwrapperName() string {
+	return lowerFirst(conf.WrappedInterface.name) + "Unwrapper"
+}
+
+// unwrapperDecls returns the declarations of the type providing the Unwrap method and its method:
+//
+//	type responseWriterUnwrapper struct {
+//		value http.ResponseWriter
+//	}
+//
+//	func (u responseWriterUnwrapper) Unwrap() http.ResponseWriter {
+//		return u.value
+//	}
+func unwrapperDecls(im *importer, conf *GenerateConfig, reserved []string) []ast.Decl {
+	var (
+		valueType     = conf.RequireInterface.Expr(im)
+		unwrapperType = instantiate(ast.NewIdent(conf.unwrapperName()), conf.TypeParams)
+		valueIdent    = ast.NewIdent("value")
+		receiverIdent = ast.NewIdent("u")
+	)
+
+	typeDecl := &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{&ast.TypeSpec{
+			Name:       ast.NewIdent(conf.unwrapperName()),
+			TypeParams: typeParamFieldList(im, conf.TypeParams),
+			Type: &ast.StructType{
+				Fields: &ast.FieldList{
+					List: []*ast.Field{{
+						Names: []*ast.Ident{valueIdent},
+						Type:  valueType,
+					}},
+				},
+			},
+		}},
+	}
+
+	methodDecl := &ast.FuncDecl{
+		Recv: &ast.FieldList{
+			List: []*ast.Field{{
+				Names: []*ast.Ident{receiverIdent},
+				Type:  unwrapperType,
+			}},
+		},
+		Name: ast.NewIdent(unwrapMethodName),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{
+					Type: valueType,
+				}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.ReturnStmt{
+				Results: []ast.Expr{&ast.SelectorExpr{
+					X:   receiverIdent,
+					Sel: valueIdent,
+				}},
+			}},
+		},
+	}
+	renameLocals(methodDecl, reserved, []*ast.Ident{receiverIdent})
+
+	return []ast.Decl{typeDecl, methodDecl}
+}
+
+// unwrapperLit returns the value of the type providing the Unwrap method, which returns the original value.
+func unwrapperLit(conf *GenerateConfig, valueIdent *ast.Ident) ast.Expr {
+	return &ast.CompositeLit{
+		Type: instantiate(ast.NewIdent(conf.unwrapperName()), conf.TypeParams),
+		Elts: []ast.Expr{&ast.KeyValueExpr{
+			Key:   ast.NewIdent("value"),
+			Value: valueIdent,
+		}},
+	}
+}
+
+// unwrapFuncDecl returns the declaration of the function retrieving the innermost value
+// by calling the Unwrap method while the value has it, up to the depth of the lookup
+// or DefaultLookupDepth so that a cyclic Unwrap chain does not loop forever:
+//
+//	func UnwrapResponseWriter(v http.ResponseWriter) http.ResponseWriter {
+//		for depth := 0; depth < 16; depth++ {
+//			u, ok := v.(interface{ Unwrap() http.ResponseWriter })
+//			if !ok {
+//				break
+//			}
+//			v = u.Unwrap()
+//		}
+//		return v
+//	}
+func unwrapFuncDecl(im *importer, conf *GenerateConfig, reserved []string) *ast.FuncDecl {
+	var (
+		valueType      = conf.RequireInterface.Expr(im)
+		valueIdent     = ast.NewIdent("v")
+		unwrapperIdent = ast.NewIdent("u")
+		okIdent        = ast.NewIdent("ok")
+		depthIdent     = ast.NewIdent("depth")
+	)
+
+	depth := conf.LookupDepth
+	if depth == 0 {
+		depth = DefaultLookupDepth
+	}
+
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent(conf.UnwrapFuncName),
+		Type: &ast.FuncType{
+			TypeParams: typeParamFieldList(im, conf.TypeParams),
+			Params: &ast.FieldList{
+				List: []*ast.Field{{
+					Names: []*ast.Ident{valueIdent},
+					Type:  valueType,
+				}},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{
+					Type: valueType,
+				}},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.ForStmt{
+				Init: &ast.AssignStmt{
+					Lhs: []ast.Expr{depthIdent},
+					Tok: token.DEFINE,
+					Rhs: []ast.Expr{&ast.BasicLit{
+						Kind:  token.INT,
+						Value: "0",
+					}},
+				},
+				Cond: &ast.BinaryExpr{
+					X:  depthIdent,
+					Op: token.LSS,
+					Y: &ast.BasicLit{
+						Kind:  token.INT,
+						Value: strconv.Itoa(depth),
+					},
+				},
+				Post: &ast.IncDecStmt{
+					X:   depthIdent,
+					Tok: token.INC,
+				},
+				Body: &ast.BlockStmt{
+					List: []ast.Stmt{
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{unwrapperIdent, okIdent},
+							Tok: token.DEFINE,
+							Rhs: []ast.Expr{&ast.TypeAssertExpr{
+								X:    valueIdent,
+								Type: unwrapInterfaceExpr(valueType),
+							}},
+						},
+						&ast.IfStmt{
+							Cond: &ast.UnaryExpr{
+								Op: token.NOT,
+								X:  okIdent,
+							},
+							Body: &ast.BlockStmt{
+								List: []ast.Stmt{&ast.BranchStmt{
+									Tok: token.BREAK,
+								}},
+							},
+						},
+						&ast.AssignStmt{
+							Lhs: []ast.Expr{valueIdent},
+							Tok: token.ASSIGN,
+							Rhs: []ast.Expr{&ast.CallExpr{
+								Fun: &ast.SelectorExpr{
+									X:   unwrapperIdent,
+									Sel: ast.NewIdent(unwrapMethodName),
+								},
+							}},
+						},
+					},
+				},
+			}, &ast.ReturnStmt{
+				Results: []ast.Expr{valueIdent},
+			}},
+		},
+	}
+	renameLocals(funcDecl, reserved, []*ast.Ident{valueIdent, unwrapperIdent, okIdent, depthIdent})
+
+	return funcDecl
+}
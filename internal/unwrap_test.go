@@ -0,0 +1,48 @@
+package iwrapper
+
+import "testing"
+
+func TestUnwrapBehavior(t *testing.T) {
+	t.Parallel()
+
+	runGenerated(t, `
+//iwrapper:target unwrap:"true" lookup:"unwrap" depth:"4"
+type Target interface {
+	//iwrapper:require
+	Writer
+	Flusher
+}
+
+// cycleWriter unwraps to itself.
+type cycleWriter struct {
+	writer
+}
+
+func (w *cycleWriter) Unwrap() Writer { return w }
+`, `package behavior
+
+import "testing"
+
+func TestUnwrap(t *testing.T) {
+	v := flushWriter{writer{"orig"}}
+	w := TargetWrapper(TargetWrapper(v, func(Writer) Target {
+		return fullWriter{writer{"wrapper"}}
+	}), func(Writer) Target {
+		return fullWriter{writer{"outer"}}
+	})
+
+	if got := UnwrapTarget(w); got != v {
+		t.Errorf("UnwrapTarget: expected %v, got %v", v, got)
+	}
+}
+
+func TestUnwrapCycle(t *testing.T) {
+	v := &cycleWriter{writer{"cycle"}}
+
+	// the cyclic chain stops at the depth
+	if got := UnwrapTarget(v); got != v {
+		t.Errorf("UnwrapTarget: expected %v, got %v", v, got)
+	}
+}
+`)
+}
@@ -0,0 +1,23 @@
+package iwrapper
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+)
+
+var (
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// wrapValueStmt returns the statement taking the wrapper value as the wrapped value,
+// so that the wrappers embed it and the optional interfaces can be asserted on it as with the wrapper function:
+//
+//	wrapped := w
+func wrapValueStmt(wrappedValueIdent, wrapperIdent *ast.Ident) ast.Stmt {
+	return &ast.AssignStmt{
+		Tok: token.DEFINE,
+		Lhs: []ast.Expr{wrappedValueIdent},
+		Rhs: []ast.Expr{wrapperIdent},
+	}
+}
@@ -0,0 +1,311 @@
+package iwrapper
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const wrapDirectivePrefix = toolPrefix + "wrap"
+
+var (
+	ErrInvalidWrap = errors.New("invalid wrap directive")
+)
+
+// wrapDirective is a target declared by a wrap directive instead of a local interface.
+//
+//	//iwrapper:wrap net/http.ResponseWriter optional:"net/http.Flusher,net/http.Hijacker"
+type wrapDirective struct {
+	pos      token.Position
+	required []string
+	tag      reflect.StructTag
+}
+
+// wrapDirectives collects the wrap directives on the package clause and the `var _` declarations of the file.
+func wrapDirectives(fset *token.FileSet, f *ast.File) ([]*wrapDirective, error) {
+	var groups []*ast.CommentGroup
+	if f.Doc != nil {
+		groups = append(groups, f.Doc)
+	}
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR || !isBlankVarDecl(genDecl) {
+			continue
+		}
+
+		if genDecl.Doc != nil {
+			groups = append(groups, genDecl.Doc)
+		}
+		for _, spec := range genDecl.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok && valueSpec.Doc != nil {
+				groups = append(groups, valueSpec.Doc)
+			}
+		}
+	}
+
+	var (
+		directives []*wrapDirective
+		errs       []error
+	)
+	for _, group := range groups {
+		for _, comment := range group.List {
+			text, ok := strings.CutPrefix(comment.Text, wrapDirectivePrefix)
+			if !ok || (text != "" && text[0] != ' ' && text[0] != '\t') {
+				continue
+			}
+
+			pos := fset.Position(comment.Pos())
+			strRequired, strTag := cutWrapTag(text)
+			required := splitList(strRequired)
+			if len(required) == 0 {
+				errs = append(errs, NewDiagnostic(pos, fmt.Errorf("%w: no required interface(%s)", ErrInvalidWrap, comment.Text)))
+				continue
+			}
+
+			directives = append(directives, &wrapDirective{
+				pos:      pos,
+				required: required,
+				tag:      reflect.StructTag(strTag),
+			})
+		}
+	}
+
+	return directives, errors.Join(errs...)
+}
+
+// cutWrapTag splits the arguments of the wrap directive into the required interfaces and the tag,
+// which starts at the first `key:"`, so that the list of the required interfaces may contain spaces.
+func cutWrapTag(text string) (string, string) {
+	for i := range len(text) {
+		if i > 0 && text[i-1] != ' ' && text[i-1] != '\t' {
+			continue
+		}
+
+		// the keys of struct tags consist of the non-control characters other than space, quote and colon
+		j := i
+		for j < len(text) && text[j] > ' ' && text[j] != ':' && text[j] != '"' && text[j] != 0x7f {
+			j++
+		}
+		if j > i && strings.HasPrefix(text[j:], `:"`) {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i:])
+		}
+	}
+
+	return strings.TrimSpace(text), ""
+}
+
+func isBlankVarDecl(genDecl *ast.GenDecl) bool {
+	for _, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			return false
+		}
+
+		for _, name := range valueSpec.Names {
+			if name.Name != "_" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// splitList splits the comma separated list, ignoring empty items.
+func splitList(text string) []string {
+	var items []string
+	for item := range strings.SplitSeq(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// parseWrapDirective creates the target of the wrap directive.
+// The combined interface of the target is declared by the generated file, named after the first required interface by default.
+func parseWrapDirective(pkg *packages.Package, directive *wrapDirective) (*ParseResult, error) {
+	optional := splitList(directive.tag.Get("optional"))
+
+	refs := make([]string, 0, len(directive.required)+len(optional))
+	refs = append(refs, directive.required...)
+	refs = append(refs, optional...)
+
+	interfaces, err := resolveInterfaceRefs(pkg, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, intrfc := range interfaces {
+		if fn := intrfc.invalidMethod(); fn != nil {
+			return nil, fmt.Errorf("invalid method(%s) of interface(%s): %w", fn.Name(), intrfc, ErrUnknownType)
+		}
+	}
+
+	requireInterfaces := interfaces[:len(directive.required)]
+	optionalInterfaces := interfaces[len(directive.required):]
+
+	typeName := directive.tag.Get("name")
+	if typeName == "" {
+		typeName = requireInterfaces[0].name
+	}
+
+	if !token.IsIdentifier(typeName) {
+		return nil, fmt.Errorf("%w: invalid name(%s)", ErrInvalidWrap, typeName)
+	}
+
+	if obj := pkg.Types.Scope().Lookup(typeName); obj != nil && !isGeneratedObject(pkg, obj) {
+		return nil, fmt.Errorf("%w: name(%s) is already declared in package(%s), set another one with name", ErrInvalidWrap, typeName, pkg.PkgPath)
+	}
+
+	result, err := newParseResult(pkg, directive.pos, typeName, directive.tag, nil, requireInterfaces, optionalInterfaces, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.Declare = true
+
+	return result, nil
+}
+
+// isGeneratedObject reports whether the object is declared in a file generated by iwrapper, such as the one generated for the directive before.
+// Files generated by other tools are sources of the package, so their names are reserved as the hand-written ones.
+func isGeneratedObject(pkg *packages.Package, obj types.Object) bool {
+	for _, f := range pkg.Syntax {
+		if f.FileStart <= obj.Pos() && obj.Pos() < f.FileEnd {
+			return len(f.Comments) != 0 &&
+				f.Comments[0].Pos() == f.FileStart &&
+				f.Comments[0].List[0].Text+"\n" == generatedHeader
+		}
+	}
+
+	return false
+}
+
+// resolveInterfaceRefs resolves the interfaces referenced as `<import path>.<name>`, or `<name>` for the local package.
+// Interfaces reachable from the imports of the package are resolved with its type information.
+// Otherwise, all referenced packages are loaded together, so that types are shared among the interfaces.
+func resolveInterfaceRefs(pkg *packages.Package, refs []string) ([]*Interface, error) {
+	lookup := func(path string) *types.Package {
+		if path == "" || path == pkg.PkgPath {
+			return pkg.Types
+		}
+
+		return findImport(pkg.Types, path)
+	}
+
+	interfaces, err := lookupInterfaceRefs(pkg.Types, refs, lookup)
+	if err == nil || !errors.Is(err, ErrUnknownType) {
+		return interfaces, err
+	}
+
+	var paths []string
+	for _, ref := range refs {
+		if path, _ := splitInterfaceRef(ref); path != "" && path != pkg.PkgPath {
+			paths = append(paths, path)
+		}
+	}
+
+	dir, err := PackageDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedImports,
+		Dir:  dir,
+	}, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	loadedMap := map[string]*types.Package{}
+	for _, loadedPkg := range loaded {
+		if len(loadedPkg.Errors) == 0 && loadedPkg.Types != nil {
+			loadedMap[loadedPkg.PkgPath] = loadedPkg.Types
+		}
+	}
+
+	return lookupInterfaceRefs(pkg.Types, refs, func(path string) *types.Package {
+		if path == "" || path == pkg.PkgPath {
+			return pkg.Types
+		}
+
+		return loadedMap[path]
+	})
+}
+
+func lookupInterfaceRefs(localPkg *types.Package, refs []string, lookup func(path string) *types.Package) ([]*Interface, error) {
+	interfaces := make([]*Interface, 0, len(refs))
+	for _, ref := range refs {
+		path, name := splitInterfaceRef(ref)
+
+		typesPkg := lookup(path)
+		if typesPkg == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, ref)
+		}
+
+		obj, ok := typesPkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() && typesPkg != localPkg {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, ref)
+		}
+
+		typ := obj.Type()
+		if !types.IsInterface(typ) {
+			return nil, fmt.Errorf("%w: %s", ErrNotInterface, ref)
+		}
+
+		if named, ok := types.Unalias(typ).(*types.Named); ok && named.TypeParams().Len() != 0 && named.TypeArgs().Len() == 0 {
+			return nil, fmt.Errorf("%w: generic interface(%s)", ErrUnsupportedType, ref)
+		}
+
+		var pkg *Package
+		if typesPkg != localPkg {
+			pkg = newPackageFromTypes(typesPkg)
+		}
+
+		interfaces = append(interfaces, NewInterface(pkg, name, NewType(typ, localPkg)))
+	}
+
+	return interfaces, nil
+}
+
+// splitInterfaceRef splits the reference of the interface into the import path and the name.
+func splitInterfaceRef(ref string) (string, string) {
+	index := strings.LastIndex(ref, ".")
+	if index < 0 {
+		return "", ref
+	}
+
+	return ref[:index], ref[index+1:]
+}
+
+// findImport finds the package imported by pkg directly or indirectly.
+func findImport(pkg *types.Package, path string) *types.Package {
+	visited := map[*types.Package]struct{}{}
+	queue := []*types.Package{pkg}
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, imported := range current.Imports() {
+			if imported.Path() == path {
+				return imported
+			}
+
+			if _, ok := visited[imported]; ok {
+				continue
+			}
+			visited[imported] = struct{}{}
+			queue = append(queue, imported)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package iwrapper
+
+import "testing"
+
+func TestCutWrapTag(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		text        string
+		required    string
+		tag         string
+	}{{
+		description: "requiredのみ",
+		text:        " net/http.ResponseWriter",
+		required:    "net/http.ResponseWriter",
+		tag:         "",
+	}, {
+		description: "requiredとtag",
+		text:        " net/http.ResponseWriter optional:\"net/http.Flusher\"",
+		required:    "net/http.ResponseWriter",
+		tag:         "optional:\"net/http.Flusher\"",
+	}, {
+		description: "空白を含むrequiredのリスト",
+		text:        " io.Reader, io.Writer optional:\"io.Closer\" name:\"ReadWriter\"",
+		required:    "io.Reader, io.Writer",
+		tag:         "optional:\"io.Closer\" name:\"ReadWriter\"",
+	}, {
+		description: "タブ区切り",
+		text:        "\tio.Reader,\tio.Writer\toptional:\"io.Closer\"",
+		required:    "io.Reader,\tio.Writer",
+		tag:         "optional:\"io.Closer\"",
+	}}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			t.Parallel()
+
+			required, tag := cutWrapTag(testCase.text)
+			if diff := diff(required, testCase.required); diff != "" {
+				t.Errorf("required diff: %s", diff)
+			}
+			if diff := diff(tag, testCase.tag); diff != "" {
+				t.Errorf("tag diff: %s", diff)
+			}
+		})
+	}
+}
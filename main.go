@@ -1,11 +1,31 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"go/token"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	iwrapper "github.com/mazrean/iwrapper/internal"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/tools/go/packages"
+)
+
+const defaultPkgDst = "iwrapper_gen.go"
+
+const (
+	exitOK = iota
+	// exitDiagnostics is the exit code when problems are found in the targets or the generation fails
+	exitDiagnostics
+	// exitUsage is the exit code when the flags are invalid
+	exitUsage
 )
 
 var (
@@ -13,52 +33,271 @@ var (
 	revision         = "Unknown"
 	versionFlag      bool
 	srcFlag, dstFlag string
+	pkgFlag          string
+	parallelFlag     int
+	jsonFlag         bool
+	checkFlag        bool
+	diffFlag         bool
 )
 
+var errOutdated = errors.New("generated file is out of date, run go generate")
+
 func init() {
 	flag.BoolVar(&versionFlag, "version", false, "show version")
 	flag.StringVar(&srcFlag, "src", "", "source file path")
-	flag.StringVar(&dstFlag, "dst", "", "destination file path")
+	flag.StringVar(&dstFlag, "dst", "", "destination file path (file name in each package directory in package mode)")
+	flag.StringVar(&pkgFlag, "pkg", "", "package pattern to generate wrappers for (e.g. ./...)")
+	flag.IntVar(&parallelFlag, "parallel", runtime.GOMAXPROCS(0), "number of packages generated concurrently in package mode")
+	flag.BoolVar(&jsonFlag, "json", false, "report diagnostics as JSON to stdout")
+	flag.BoolVar(&checkFlag, "check", false, "check that generated files are up to date without writing them")
+	flag.BoolVar(&diffFlag, "diff", false, "print the unified diff of out-of-date generated files without writing them (implies -check)")
 }
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	flag.Parse()
 
 	if versionFlag {
 		fmt.Printf("iwrapper %s (revision: %s)\n", version, revision)
-		return
+		return exitOK
+	}
+
+	if diffFlag && jsonFlag {
+		return usageError("-diff and -json are exclusive")
+	}
+
+	patterns := flag.Args()
+	if len(pkgFlag) != 0 {
+		patterns = append(patterns, pkgFlag)
+	}
+
+	if len(patterns) != 0 {
+		if len(srcFlag) != 0 {
+			return usageError("source file path and package patterns are exclusive")
+		}
+
+		dst := dstFlag
+		if len(dst) == 0 {
+			dst = defaultPkgDst
+		}
+		if filepath.Base(dst) != dst {
+			return usageError("destination must be a file name in package mode")
+		}
+
+		return report(generatePackages(patterns, dst))
 	}
 
 	if len(srcFlag) == 0 {
-		panic("source file path is required")
+		return usageError("source file path is required")
 	}
 	if len(dstFlag) == 0 {
-		panic("destination file path is required")
+		return usageError("destination file path is required")
+	}
+
+	return report(generateFile())
+}
+
+func usageError(msg string) int {
+	fmt.Fprintf(os.Stderr, "iwrapper: %s\n", msg)
+	flag.Usage()
+
+	return exitUsage
+}
+
+// report prints all diagnostics of the error and returns the exit code.
+func report(err error) int {
+	diagnostics := iwrapper.Diagnostics(err)
+
+	if jsonFlag {
+		if diagnostics == nil {
+			diagnostics = []*iwrapper.Diagnostic{}
+		}
+
+		if err := json.NewEncoder(os.Stdout).Encode(diagnostics); err != nil {
+			fmt.Fprintf(os.Stderr, "iwrapper: failed to encode diagnostics: %v\n", err)
+			return exitDiagnostics
+		}
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintln(os.Stderr, diagnostic)
+		}
+	}
+
+	if len(diagnostics) != 0 {
+		return exitDiagnostics
 	}
 
-	f, err := os.Open(srcFlag)
+	return exitOK
+}
+
+func generateFile() error {
+	pkgName, results, err := iwrapper.ParseTarget(srcFlag)
 	if err != nil {
-		panic(fmt.Errorf("failed to open source file: %w", err))
+		return fmt.Errorf("failed to parse target: %w", err)
 	}
-	defer f.Close()
 
-	pkgName, results, err := iwrapper.ParseTarget(f)
+	confs, err := iwrapper.Convert(results)
+	if err != nil {
+		return fmt.Errorf("failed to convert: %w", err)
+	}
+
+	diff, err := writeOutput(dstFlag, pkgName, confs)
+	os.Stdout.Write(diff)
+
+	return err
+}
+
+// generatePackages generates the wrappers of all packages, collecting the problems of every package.
+func generatePackages(patterns []string, dst string) error {
+	pkgs, err := iwrapper.LoadPackages(patterns...)
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	// errors are collected instead of returned, so that a failing package does not hide the others
+	errs := make([]error, len(pkgs))
+	diffs := make([][]byte, len(pkgs))
+	var eg errgroup.Group
+	eg.SetLimit(max(parallelFlag, 1))
+	for i, pkg := range pkgs {
+		eg.Go(func() error {
+			diffs[i], errs[i] = generatePackage(pkg, dst)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	// diffs are printed in the order of the packages to keep the output stable
+	for _, diff := range diffs {
+		os.Stdout.Write(diff)
+	}
+
+	return errors.Join(errs...)
+}
+
+func generatePackage(pkg *packages.Package, dst string) ([]byte, error) {
+	results, err := iwrapper.ParsePackage(pkg)
 	if err != nil {
-		panic(fmt.Errorf("failed to parse target: %w", err))
+		return nil, fmt.Errorf("failed to parse package(%s): %w", pkg.PkgPath, err)
+	}
+
+	// packages without targets have no generated file, and the file of the targets removed since the last run is stale
+	if len(results) == 0 {
+		dir, err := iwrapper.PackageDir(pkg)
+		if err != nil {
+			return nil, nil
+		}
+
+		diff, err := removeStale(filepath.Join(dir, dst))
+		if err != nil {
+			return diff, fmt.Errorf("failed to remove stale wrapper(%s): %w", pkg.PkgPath, err)
+		}
+
+		return nil, nil
 	}
 
 	confs, err := iwrapper.Convert(results)
 	if err != nil {
-		panic(fmt.Errorf("failed to convert: %w", err))
+		return nil, fmt.Errorf("failed to convert package(%s): %w", pkg.PkgPath, err)
+	}
+
+	dir, err := iwrapper.PackageDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := writeOutput(filepath.Join(dir, dst), pkg.Name, confs)
+	if err != nil {
+		return diff, fmt.Errorf("failed to generate wrapper(%s): %w", pkg.PkgPath, err)
+	}
+
+	return nil, nil
+}
+
+// writeOutput renders the wrappers and writes them to path only if they compile with the package.
+// Files with the same content are not rewritten to keep their modification time.
+// With -check or -diff, the file is not written and the mismatch is reported as an error, with the diff for -diff.
+func writeOutput(path, pkgName string, confs []*iwrapper.GenerateConfig) ([]byte, error) {
+	output, err := iwrapper.Render(pkgName, confs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render wrapper: %w", err)
+	}
+
+	if err := output.Check(path); err != nil {
+		return nil, fmt.Errorf("failed to check wrapper: %w", err)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read destination file: %w", err)
+	}
+
+	if err == nil && bytes.Equal(current, output.Src) {
+		return nil, nil
+	}
+
+	if checkFlag || diffFlag {
+		var diff []byte
+		if diffFlag {
+			diff = iwrapper.UnifiedDiff(displayPath(path), current, output.Src)
+		}
+
+		return diff, iwrapper.NewDiagnostic(token.Position{Filename: path, Line: 1}, errOutdated)
+	}
+
+	if err := iwrapper.WriteFile(path, output.Src); err != nil {
+		return nil, fmt.Errorf("failed to write destination file: %w", err)
+	}
+
+	return nil, nil
+}
+
+// removeStale removes the file generated by iwrapper at path, leaving the files written by hand untouched.
+// With -check or -diff, the file is not removed and its existence is reported as an error, with the diff for -diff.
+func removeStale(path string) ([]byte, error) {
+	if !iwrapper.IsGeneratedFile(path) {
+		return nil, nil
+	}
+
+	if checkFlag || diffFlag {
+		var diff []byte
+		if diffFlag {
+			current, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read destination file: %w", err)
+			}
+			diff = iwrapper.UnifiedDiff(displayPath(path), current, nil)
+		}
+
+		return diff, iwrapper.NewDiagnostic(token.Position{Filename: path, Line: 1}, errOutdated)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("failed to remove destination file: %w", err)
+	}
+
+	return nil, nil
+}
+
+// displayPath returns the path relative to the working directory if it is inside, so that diffs can be applied with patch.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
 	}
 
-	f, err = os.Create(dstFlag)
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		panic(fmt.Errorf("failed to create destination file: %w", err))
+		return path
 	}
-	defer f.Close()
 
-	if err := iwrapper.Generate(f, pkgName, confs); err != nil {
-		panic(fmt.Errorf("failed to generate wrapper: %w", err))
+	rel, err := filepath.Rel(wd, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
 	}
+
+	return rel
 }